@@ -0,0 +1,81 @@
+package youtube
+
+import (
+	"context"
+	"time"
+)
+
+// PlayerAPI is the full method set of *Player. Applications should depend on
+// it rather than on *Player so the player can be replaced by a test double
+// (see package youtubetest) or wrapped with extra behavior.
+type PlayerAPI interface {
+	Controller
+
+	SetSize(width int, height int) JSValue
+	VideoData() *VideoData
+	AddEventListener(event EventType, fn func(event *Event)) Listener
+	RemoveEventListener(l Listener)
+	Iframe() JSValue
+}
+
+// Controller is the part of PlayerAPI that does not deal with raw JS values.
+// Logic written against it also runs with the headless player of package
+// ytsim.
+type Controller interface {
+	// Queueing functions
+	LoadVideoByID(vid string, startSec float64, q Quality)
+	LoadVideoByID2(params *LoadByIDOptions)
+	CueVideoByID(vid string, startSec float64, q Quality)
+	CueVideoByID2(params *LoadByIDOptions)
+	LoadVideoByURL(url string, startSec float64, q Quality)
+	LoadVideoByURL2(params *LoadByURLOptions)
+	CuePlaylist(ids []string, index int, startSec float64, q Quality)
+	CuePlaylist2(params *CuePlaylistOptions)
+	LoadPlaylist(ids []string, index int, startSec float64, q Quality)
+	LoadPlaylist2(params *CuePlaylistOptions)
+
+	// Playback controls and player settings
+	PlayVideo()
+	PauseVideo()
+	StopVideo()
+	SeekTo(seconds float64, allowSeekAhead bool)
+	NextVideo()
+	PreviousVideo()
+	PlayVideoAt(index int)
+	Mute()
+	UnMute()
+	IsMuted() bool
+	SetVolume(vol int)
+	Volume() int
+	PlaybackRate() float64
+	SetPlaybackRate(suggestedRate float64)
+	AvailablePlaybackRates() []float64
+	SetLoop(val bool)
+	SetShuffle(val bool)
+
+	// Playback status and video information
+	VideoLoadedFraction() float64
+	PlayerState() PlayerState
+	CurrentTime() float64
+	PlaybackQuality() Quality
+	SetPlaybackQuality(suggested Quality)
+	AvailableQualityLevels() []Quality
+	Duration() float64
+	VideoURL() string
+	VideoEmbedCode() string
+	Playlist() []string
+	PlaylistIndex() int
+
+	// Events
+	Events(ctx context.Context, types ...EventType) <-chan TypedEvent
+	WaitReady(ctx context.Context) error
+	WaitForState(ctx context.Context, state PlayerState) error
+	WaitUntilTime(ctx context.Context, t time.Duration) error
+
+	// Lifecycle
+	Lifecycle() Lifecycle
+	OnDestroy(fn func()) (remove func())
+	Destroy()
+}
+
+var _ PlayerAPI = (*Player)(nil)
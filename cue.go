@@ -0,0 +1,297 @@
+package youtube
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCueInterval is the polling interval of a CueTrack without Interval
+const DefaultCueInterval = 250 * time.Millisecond
+
+// seekTolerance is how far the position may drift from where the playback
+// would have brought it before the move is taken for a seek
+const seekTolerance = time.Second
+
+// Cue is an entry of a CueTrack. The playback is in the cue from Start until
+// End; a cue without End is a point, entered and exited at once when the
+// playback crosses Start.
+type Cue struct {
+	Start   time.Duration
+	End     time.Duration
+	Payload interface{}
+}
+
+func (c Cue) isPoint() bool {
+	return c.End <= c.Start
+}
+
+func (c Cue) contains(pos time.Duration) bool {
+	if c.isPoint() {
+		return pos == c.Start
+	}
+	return c.Start <= pos && pos < c.End
+}
+
+// MissedCuePolicy says what a CueTrack does with the cues a forward seek jumps
+// over
+type MissedCuePolicy int
+
+const (
+	// SkipMissedCues fires nothing for the cues jumped over
+	SkipMissedCues MissedCuePolicy = iota
+	// FireMissedCues enters and exits the cues jumped over, in order
+	FireMissedCues
+)
+
+// CueTrack fires callbacks as the playback of a player enters and exits its
+// cues:
+//
+//	track := &youtube.CueTrack{
+//		OnEnter: func(c youtube.Cue) { showQuiz(c.Payload) },
+//		OnExit:  func(c youtube.Cue) { hideQuiz(c.Payload) },
+//	}
+//	track.Add(2*time.Minute+15*time.Second, quiz)
+//	detach := track.Attach(p)
+//
+// The playback moving backwards, as after a seek back, fires the cues again
+// when they are crossed again. The cues are exited when the player switches
+// to another video.
+type CueTrack struct {
+	// OnEnter and OnExit are called from the goroutine polling the player
+	OnEnter func(Cue)
+	OnExit  func(Cue)
+	// Missed says what happens to the cues jumped over by a forward seek
+	Missed MissedCuePolicy
+	// VideoID limits the cues to a video, e.g. of a playlist. The cues apply
+	// to every video if it is empty.
+	VideoID string
+	// Interval is the polling interval, DefaultCueInterval if zero. The
+	// track also wakes up when the playback is due to reach the next cue.
+	Interval time.Duration
+
+	mu      sync.Mutex
+	entries []*cueEntry
+}
+
+type cueEntry struct {
+	Cue
+	active bool
+}
+
+// cueBoundary is an enter or exit to fire
+type cueBoundary struct {
+	at    time.Duration
+	order int
+	entry *cueEntry
+	enter bool
+}
+
+// Add adds a point cue at at
+func (t *CueTrack) Add(at time.Duration, payload interface{}) {
+	t.AddCue(Cue{Start: at, Payload: payload})
+}
+
+// AddRange adds a cue from start until end
+func (t *CueTrack) AddRange(start, end time.Duration, payload interface{}) {
+	t.AddCue(Cue{Start: start, End: end, Payload: payload})
+}
+
+// AddCue adds a cue. Cues can be added while the track is attached; they are
+// fired once the playback crosses them.
+func (t *CueTrack) AddCue(c Cue) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i := sort.Search(len(t.entries), func(i int) bool { return t.entries[i].Start > c.Start })
+	t.entries = append(t.entries, nil)
+	copy(t.entries[i+1:], t.entries[i:])
+	t.entries[i] = &cueEntry{Cue: c}
+}
+
+// Cues returns the cues of the track, sorted by Start
+func (t *CueTrack) Cues() []Cue {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cues := make([]Cue, len(t.entries))
+	for i, e := range t.entries {
+		cues[i] = e.Cue
+	}
+	return cues
+}
+
+// Attach starts following the playback of c. It stops when detach is called
+// or c is destroyed. A track is attached to one player at a time.
+func (t *CueTrack) Attach(c Controller) (detach func()) {
+	interval := t.Interval
+	if interval <= 0 {
+		interval = DefaultCueInterval
+	}
+	var (
+		wake   = make(chan struct{}, 1)
+		timer  *time.Timer
+		prev   PlayerStatus
+		polled time.Time
+		first  = true
+	)
+	stop := startPolling(c, interval, wake, func(s PlayerStatus) {
+		now := time.Now()
+		switch {
+		case first || s.VideoID != prev.VideoID || s.PlaylistIndex != prev.PlaylistIndex:
+			// a new video starts from scratch
+			t.exitAll()
+			if t.applies(s) {
+				t.seek(-1, s.Position)
+			}
+		case !t.applies(s):
+		default:
+			s.Position = t.advance(prev, s, now.Sub(polled))
+		}
+		prev, polled, first = s, now, false
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if d, ok := t.untilNext(s); ok && d < interval {
+			timer = time.AfterFunc(d, func() {
+				select {
+				case wake <- struct{}{}:
+				default:
+				}
+			})
+		}
+	})
+	return stop
+}
+
+func (t *CueTrack) applies(s PlayerStatus) bool {
+	return t.VideoID == "" || t.VideoID == s.VideoID
+}
+
+// advance handles the move of the playback from prev to s, elapsed after it.
+// It returns the position to take as the next starting point.
+func (t *CueTrack) advance(prev, s PlayerStatus, elapsed time.Duration) time.Duration {
+	from, to := prev.Position, s.Position
+	expected := from
+	if prev.State == Playing {
+		expected += time.Duration(float64(elapsed) * prev.PlaybackRate)
+	}
+	switch diff := to - expected; {
+	case to < from && from-to <= seekTolerance && diff > -seekTolerance:
+		// jitter of the reported time, not a seek back: keep the furthest
+		// position so the cues crossed are not fired twice
+		return from
+	case diff > seekTolerance || diff < -seekTolerance:
+		t.seek(from, to)
+	default:
+		t.cross(from, to)
+	}
+	return to
+}
+
+// cross fires the cues crossed by the playback moving from from to to
+func (t *CueTrack) cross(from, to time.Duration) {
+	t.mu.Lock()
+	var fire []cueBoundary
+	for _, e := range t.entries {
+		startCrossed := from < e.Start && e.Start <= to
+		switch {
+		case e.isPoint():
+			if startCrossed {
+				fire = append(fire,
+					cueBoundary{at: e.Start, order: 1, entry: e, enter: true},
+					cueBoundary{at: e.Start, order: 2, entry: e})
+			}
+		default:
+			if startCrossed && !e.active {
+				fire = append(fire, cueBoundary{at: e.Start, order: 1, entry: e, enter: true})
+			}
+			if from < e.End && e.End <= to && (e.active || startCrossed) {
+				fire = append(fire, cueBoundary{at: e.End, entry: e})
+			}
+		}
+	}
+	t.mu.Unlock()
+	// at the same time, the cues ending are exited before the ones starting
+	// are entered
+	sort.SliceStable(fire, func(i, j int) bool {
+		if fire[i].at != fire[j].at {
+			return fire[i].at < fire[j].at
+		}
+		return fire[i].order < fire[j].order
+	})
+	t.fire(fire)
+}
+
+// seek fires the cues after the playback jumped from from to to
+func (t *CueTrack) seek(from, to time.Duration) {
+	t.mu.Lock()
+	var exits, missed, enters []cueBoundary
+	for _, e := range t.entries {
+		switch {
+		case e.active && !e.contains(to):
+			exits = append(exits, cueBoundary{at: e.End, entry: e})
+		case !e.active && e.contains(to):
+			enters = append(enters, cueBoundary{entry: e, enter: true})
+			if e.isPoint() {
+				enters = append(enters, cueBoundary{entry: e})
+			}
+		case t.Missed == FireMissedCues && !e.active && from < e.Start && e.Start < to:
+			missed = append(missed, cueBoundary{entry: e, enter: true}, cueBoundary{entry: e})
+		}
+	}
+	t.mu.Unlock()
+	sort.SliceStable(exits, func(i, j int) bool { return exits[i].at < exits[j].at })
+	t.fire(exits)
+	t.fire(missed)
+	t.fire(enters)
+}
+
+// exitAll exits every active cue
+func (t *CueTrack) exitAll() {
+	t.mu.Lock()
+	var exits []cueBoundary
+	for _, e := range t.entries {
+		if e.active {
+			exits = append(exits, cueBoundary{entry: e})
+		}
+	}
+	t.mu.Unlock()
+	t.fire(exits)
+}
+
+func (t *CueTrack) fire(bs []cueBoundary) {
+	for _, b := range bs {
+		t.mu.Lock()
+		b.entry.active = b.enter && !b.entry.isPoint()
+		t.mu.Unlock()
+		fn := t.OnExit
+		if b.enter {
+			fn = t.OnEnter
+		}
+		if fn != nil {
+			fn(b.entry.Cue)
+		}
+	}
+}
+
+// untilNext returns the time until the playback reaches the next start or
+// end of a cue, at the current rate
+func (t *CueTrack) untilNext(s PlayerStatus) (time.Duration, bool) {
+	if s.State != Playing || s.PlaybackRate <= 0 || !t.applies(s) {
+		return 0, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	next := time.Duration(-1)
+	for _, e := range t.entries {
+		for _, at := range []time.Duration{e.Start, e.End} {
+			if at > s.Position && (next < 0 || at < next) && (at == e.Start || !e.isPoint()) {
+				next = at
+			}
+		}
+	}
+	if next < 0 {
+		return 0, false
+	}
+	return time.Duration(float64(next-s.Position) / s.PlaybackRate), true
+}
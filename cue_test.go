@@ -0,0 +1,177 @@
+package youtube_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/ytsim"
+)
+
+const cueInterval = 10 * time.Millisecond
+
+// attachCues attaches track to p, sending "enter <payload>" and
+// "exit <payload>" to the returned channel as the cues fire
+func attachCues(p youtube.Controller, track *youtube.CueTrack) (<-chan string, func()) {
+	fired := make(chan string, 100)
+	track.OnEnter = func(c youtube.Cue) { fired <- fmt.Sprint("enter ", c.Payload) }
+	track.OnExit = func(c youtube.Cue) { fired <- fmt.Sprint("exit ", c.Payload) }
+	track.Interval = cueInterval
+	detach := track.Attach(p)
+	// let the first poll take the initial position
+	time.Sleep(3 * cueInterval)
+	return fired, detach
+}
+
+// expectCues checks that exactly want fire, in order, then nothing for a few
+// intervals
+func expectCues(t *testing.T, fired <-chan string, when string, want ...string) {
+	t.Helper()
+	var got []string
+	timeout := time.After(time.Second)
+collect:
+	for len(got) < len(want) {
+		select {
+		case s := <-fired:
+			got = append(got, s)
+		case <-timeout:
+			break collect
+		}
+	}
+	select {
+	case s := <-fired:
+		got = append(got, s)
+	case <-time.After(5 * cueInterval):
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s: fired %q, want %q", when, got, want)
+	}
+}
+
+// step plays half a second of p's video, whatever its playback rate
+func step(p *ytsim.Player) {
+	p.Advance(time.Duration(float64(500*time.Millisecond) / p.PlaybackRate()))
+}
+
+func TestCueTrackFiresInOrder(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	track := &youtube.CueTrack{}
+	track.Add(time.Second, "p")
+	track.AddRange(time.Second, 2*time.Second, "r")
+	track.AddRange(2*time.Second, 3*time.Second, "s")
+	track.Add(2*time.Second, "q")
+	fired, detach := attachCues(p, track)
+	defer detach()
+
+	steps := [][]string{
+		nil,
+		// the points are exited once everything starting with them is entered
+		{"enter p", "enter r", "exit p"},
+		nil,
+		// the ranges ending are exited before the ones starting are entered
+		{"exit r", "enter s", "enter q", "exit q"},
+		nil,
+		{"exit s"},
+	}
+	for i, want := range steps {
+		if i == 2 {
+			p.SetPlaybackRate(2)
+			expectCues(t, fired, "rate change")
+		}
+		step(p)
+		expectCues(t, fired, fmt.Sprintf("at %v", p.CurrentTime()), want...)
+	}
+}
+
+func TestCueTrackSeekBack(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	track := &youtube.CueTrack{}
+	track.Add(time.Second, "p")
+	track.AddRange(2*time.Second, 4*time.Second, "r")
+	fired, detach := attachCues(p, track)
+	defer detach()
+
+	step(p)
+	expectCues(t, fired, "at 0.5s")
+	step(p)
+	expectCues(t, fired, "at 1s", "enter p", "exit p")
+	step(p)
+	expectCues(t, fired, "at 1.5s")
+
+	// a small move back is taken for jitter of the reported time
+	p.SeekTo(1.2, true)
+	expectCues(t, fired, "jitter back to 1.2s")
+	step(p)
+	expectCues(t, fired, "at 1.7s")
+
+	// a seek back fires nothing by itself; the cue fires again when crossed
+	p.SeekTo(0, true)
+	expectCues(t, fired, "seek back to 0")
+	step(p)
+	expectCues(t, fired, "at 0.5s again")
+	step(p)
+	expectCues(t, fired, "at 1s again", "enter p", "exit p")
+
+	p.SeekTo(2.5, true)
+	expectCues(t, fired, "seek into the range", "enter r")
+	p.SeekTo(0.5, true)
+	expectCues(t, fired, "seek back out of the range", "exit r")
+}
+
+func TestCueTrackForwardSeek(t *testing.T) {
+	tests := []struct {
+		policy youtube.MissedCuePolicy
+		want   []string
+	}{
+		{youtube.SkipMissedCues, []string{"enter s"}},
+		{youtube.FireMissedCues, []string{"enter p", "exit p", "enter r", "exit r", "enter s"}},
+	}
+	for _, tt := range tests {
+		p := ytsim.NewPlayer(ytsim.Config{})
+		p.Ready()
+		p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+		track := &youtube.CueTrack{Missed: tt.policy}
+		track.Add(2*time.Second, "p")
+		track.AddRange(3*time.Second, 4*time.Second, "r")
+		track.AddRange(9*time.Second, 12*time.Second, "s")
+		track.Add(20*time.Second, "after")
+		fired, detach := attachCues(p, track)
+
+		p.SeekTo(10, true)
+		expectCues(t, fired, fmt.Sprintf("policy %d: seek to 10s", tt.policy), tt.want...)
+		detach()
+	}
+}
+
+func TestCueTrackExitsOnVideoSwitch(t *testing.T) {
+	tests := []struct {
+		videoID string
+		want    []string
+	}{
+		// the cues apply to the next video as well
+		{"", []string{"enter r"}},
+		{"dQw4w9WgXcQ", nil},
+	}
+	for _, tt := range tests {
+		p := ytsim.NewPlayer(ytsim.Config{})
+		p.Ready()
+		p.LoadPlaylist([]string{"dQw4w9WgXcQ", "9bZkp7q19f0"}, 0, 0, youtube.Auto)
+		track := &youtube.CueTrack{VideoID: tt.videoID}
+		track.AddRange(500*time.Millisecond, 5*time.Second, "r")
+		fired, detach := attachCues(p, track)
+
+		step(p)
+		expectCues(t, fired, fmt.Sprintf("video %q: in the range", tt.videoID), "enter r")
+		p.NextVideo()
+		expectCues(t, fired, fmt.Sprintf("video %q: next video", tt.videoID), "exit r")
+		step(p)
+		expectCues(t, fired, fmt.Sprintf("video %q: in the range of the next video", tt.videoID), tt.want...)
+		detach()
+	}
+}
@@ -0,0 +1,162 @@
+package youtube
+
+import "time"
+
+// TypedEvent is implemented by the decoded payloads of every player event.
+// Use a type switch to branch on the concrete event.
+type TypedEvent interface {
+	EventType() EventType
+}
+
+// ReadyEvent is fired when the player has finished loading and is ready to
+// receive API calls
+type ReadyEvent struct{}
+
+// StateChangeEvent is fired whenever the player's state changes
+type StateChangeEvent struct {
+	State PlayerState
+}
+
+// QualityChangeEvent is fired whenever the video playback quality changes
+type QualityChangeEvent struct {
+	Quality Quality
+}
+
+// RateChangeEvent is fired whenever the video playback rate changes
+type RateChangeEvent struct {
+	Rate float64
+}
+
+// ErrorEvent is fired if an error occurs in the player
+type ErrorEvent struct {
+	Err Error
+	// VideoID, PlaylistIndex and Time are read from the player when the
+	// event is decoded. PlaylistIndex is -1 when no playlist is playing.
+	VideoID       string
+	PlaylistIndex int
+	Time          time.Duration
+}
+
+// PlayerError returns the error of the event along with its context
+func (e ErrorEvent) PlayerError() *PlayerError {
+	return &PlayerError{
+		Err:           e.Err,
+		VideoID:       e.VideoID,
+		PlaylistIndex: e.PlaylistIndex,
+		Time:          e.Time,
+	}
+}
+
+// APIChangeEvent is fired to indicate that the player has loaded (or unloaded)
+// a module with exposed API methods
+type APIChangeEvent struct{}
+
+func (ReadyEvent) EventType() EventType         { return OnReady }
+func (StateChangeEvent) EventType() EventType   { return OnStateChange }
+func (QualityChangeEvent) EventType() EventType { return OnPlaybackQualityChange }
+func (RateChangeEvent) EventType() EventType    { return OnPlaybackRateChange }
+func (ErrorEvent) EventType() EventType         { return OnError }
+func (APIChangeEvent) EventType() EventType     { return OnApiChange }
+
+// AsStateChange decodes the event data of an onStateChange event
+func (e *Event) AsStateChange() StateChangeEvent {
+	return StateChangeEvent{State: PlayerState(e.Data.Int())}
+}
+
+// AsQualityChange decodes the event data of an onPlaybackQualityChange event
+func (e *Event) AsQualityChange() QualityChangeEvent {
+	return QualityChangeEvent{Quality: Quality(e.Data.String())}
+}
+
+// AsRateChange decodes the event data of an onPlaybackRateChange event
+func (e *Event) AsRateChange() RateChangeEvent {
+	return RateChangeEvent{Rate: e.Data.Float()}
+}
+
+// AsError decodes the event data of an onError event, and reads the video,
+// playlist index and time of the failure from the target player
+func (e *Event) AsError() ErrorEvent {
+	ev := ErrorEvent{Err: Error(e.Data.Int()), PlaylistIndex: -1}
+	if e.Target == nil {
+		return ev
+	}
+	if vd := e.Target.VideoData(); vd != nil {
+		ev.VideoID = vd.VideoID
+	}
+	if len(e.Target.Playlist()) > 0 {
+		ev.PlaylistIndex = e.Target.PlaylistIndex()
+	}
+	ev.Time = e.Target.Position()
+	return ev
+}
+
+// Typed decodes the event data according to the type of the event the
+// listener was registered for. It returns nil for an unknown event type.
+func (e *Event) Typed(t EventType) TypedEvent {
+	switch t {
+	case OnReady:
+		return ReadyEvent{}
+	case OnStateChange:
+		return e.AsStateChange()
+	case OnPlaybackQualityChange:
+		return e.AsQualityChange()
+	case OnPlaybackRateChange:
+		return e.AsRateChange()
+	case OnError:
+		return e.AsError()
+	case OnApiChange:
+		return APIChangeEvent{}
+	default:
+		return nil
+	}
+}
+
+// HandleReady sets the onReady callback with a typed handler
+func (pe *PlayerEvents) HandleReady(fn func(p *Player, e ReadyEvent)) {
+	pe.OnReady = func(e *Event) { fn(e.Target, ReadyEvent{}) }
+}
+
+// set sets the callback of event
+func (pe *PlayerEvents) set(event EventType, fn func(*Event)) {
+	switch event {
+	case OnReady:
+		pe.OnReady = fn
+	case OnStateChange:
+		pe.OnStateChange = fn
+	case OnPlaybackQualityChange:
+		pe.OnPlaybackQualityChange = fn
+	case OnPlaybackRateChange:
+		pe.OnPlaybackRateChange = fn
+	case OnError:
+		pe.OnError = fn
+	case OnApiChange:
+		pe.OnAPIChange = fn
+	}
+}
+
+// HandleStateChange sets the onStateChange callback with a typed handler
+func (pe *PlayerEvents) HandleStateChange(fn func(p *Player, e StateChangeEvent)) {
+	pe.OnStateChange = func(e *Event) { fn(e.Target, e.AsStateChange()) }
+}
+
+// HandlePlaybackQualityChange sets the onPlaybackQualityChange callback with a
+// typed handler
+func (pe *PlayerEvents) HandlePlaybackQualityChange(fn func(p *Player, e QualityChangeEvent)) {
+	pe.OnPlaybackQualityChange = func(e *Event) { fn(e.Target, e.AsQualityChange()) }
+}
+
+// HandlePlaybackRateChange sets the onPlaybackRateChange callback with a typed
+// handler
+func (pe *PlayerEvents) HandlePlaybackRateChange(fn func(p *Player, e RateChangeEvent)) {
+	pe.OnPlaybackRateChange = func(e *Event) { fn(e.Target, e.AsRateChange()) }
+}
+
+// HandleError sets the onError callback with a typed handler
+func (pe *PlayerEvents) HandleError(fn func(p *Player, e ErrorEvent)) {
+	pe.OnError = func(e *Event) { fn(e.Target, e.AsError()) }
+}
+
+// HandleAPIChange sets the onApiChange callback with a typed handler
+func (pe *PlayerEvents) HandleAPIChange(fn func(p *Player, e APIChangeEvent)) {
+	pe.OnAPIChange = func(e *Event) { fn(e.Target, APIChangeEvent{}) }
+}
@@ -0,0 +1,236 @@
+package youtube
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Hub tracks the lifecycle of a player and delivers its typed events to
+// subscribers. The bindings keep one for every player; it is exported for
+// test doubles, such as those of the youtubetest and ytsim packages, to
+// behave like the bindings. Create one with NewHub.
+type Hub struct {
+	mu      sync.Mutex
+	subs    map[int]*subscriber
+	nextSub int
+	hooks   []*func()
+
+	readyOnce   sync.Once
+	ready       chan struct{} // closed by MarkReady
+	destroyOnce sync.Once
+	destroyed   chan struct{} // closed by Destroy
+}
+
+type subscriber struct {
+	types map[EventType]bool
+	fn    func(TypedEvent)
+}
+
+// NewHub returns the hub of a player being created
+func NewHub() *Hub {
+	return &Hub{
+		subs:      make(map[int]*subscriber),
+		ready:     make(chan struct{}),
+		destroyed: make(chan struct{}),
+	}
+}
+
+// MarkReady moves the player to LifecycleReady, releasing WaitReady. Calling
+// it again does nothing.
+func (h *Hub) MarkReady() {
+	h.readyOnce.Do(func() { close(h.ready) })
+}
+
+// Done returns a channel closed once the player is destroyed
+func (h *Hub) Done() <-chan struct{} {
+	return h.destroyed
+}
+
+// Lifecycle returns the stage of the player's life
+func (h *Hub) Lifecycle() Lifecycle {
+	select {
+	case <-h.destroyed:
+		return LifecycleDestroyed
+	default:
+	}
+	select {
+	case <-h.ready:
+		return LifecycleReady
+	default:
+		return LifecycleCreating
+	}
+}
+
+// WaitReady blocks until MarkReady is called. It returns ErrDestroyed if the
+// player is destroyed first, or ctx.Err() if ctx is done first.
+func (h *Hub) WaitReady(ctx context.Context) error {
+	select {
+	case <-h.destroyed:
+		return ErrDestroyed
+	default:
+	}
+	select {
+	case <-h.ready:
+		return nil
+	case <-h.destroyed:
+		return ErrDestroyed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe calls fn synchronously from Emit with every event of the given
+// types, or of every type if none is given. The returned func unsubscribes.
+func (h *Hub) Subscribe(fn func(TypedEvent), types ...EventType) (cancel func()) {
+	s := &subscriber{fn: fn}
+	if len(types) > 0 {
+		s.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+	h.mu.Lock()
+	id := h.nextSub
+	h.nextSub++
+	h.subs[id] = s
+	h.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, id)
+			h.mu.Unlock()
+		})
+	}
+}
+
+// Emit calls the subscribers of the event type in the order they subscribed.
+// A subscriber removed by an earlier one is not called.
+func (h *Hub) Emit(ev TypedEvent) {
+	h.mu.Lock()
+	ids := make([]int, 0, len(h.subs))
+	for id := range h.subs {
+		ids = append(ids, id)
+	}
+	h.mu.Unlock()
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		h.mu.Lock()
+		s, ok := h.subs[id]
+		h.mu.Unlock()
+		if ok && (s.types == nil || s.types[ev.EventType()]) {
+			s.fn(ev)
+		}
+	}
+}
+
+// Events returns a channel of the emitted events of the given types, or of
+// every type if none is given. Like Player.Events, it holds EventStreamBuffer
+// events, drops the oldest one when the receiver falls behind, and is closed
+// once ctx is done or the player is destroyed.
+func (h *Hub) Events(ctx context.Context, types ...EventType) <-chan TypedEvent {
+	return newStream(ctx, h.destroyed, func(send func(TypedEvent)) func() {
+		return h.Subscribe(send, types...)
+	})
+}
+
+// OnDestroy registers fn to be called by Destroy, or calls it right away if
+// the player is already destroyed. The returned func unregisters fn.
+func (h *Hub) OnDestroy(fn func()) (remove func()) {
+	hook := &fn
+	h.mu.Lock()
+	select {
+	case <-h.destroyed:
+		h.mu.Unlock()
+		fn()
+		return func() {}
+	default:
+	}
+	h.hooks = append(h.hooks, hook)
+	h.mu.Unlock()
+	return func() {
+		h.mu.Lock()
+		*hook = nil
+		h.mu.Unlock()
+	}
+}
+
+// Destroy moves the player to LifecycleDestroyed: pending waits return
+// ErrDestroyed and event streams are closed. It then calls release if not
+// nil, drops the subscribers and calls the OnDestroy hooks in the order they
+// were added, even if release panics. It reports whether the player was not
+// destroyed yet; calling it again does nothing.
+func (h *Hub) Destroy(release func()) (first bool) {
+	h.destroyOnce.Do(func() {
+		close(h.destroyed)
+		first = true
+	})
+	if !first {
+		return false
+	}
+	defer func() {
+		h.mu.Lock()
+		h.subs = make(map[int]*subscriber)
+		fns := make([]func(), 0, len(h.hooks))
+		for _, hook := range h.hooks {
+			if *hook != nil {
+				fns = append(fns, *hook)
+			}
+		}
+		h.hooks = nil
+		h.mu.Unlock()
+		for _, fn := range fns {
+			fn()
+		}
+	}()
+	if release != nil {
+		release()
+	}
+	return true
+}
+
+// newStream returns a channel fed by the send func passed to subscribe,
+// which never blocks: when the channel is full, the oldest event is dropped
+// to make room. The func returned by subscribe is called and the channel is
+// closed once ctx or done is done.
+func newStream(ctx context.Context, done <-chan struct{}, subscribe func(send func(TypedEvent)) (cancel func())) <-chan TypedEvent {
+	var (
+		mu     sync.Mutex
+		closed bool
+		ch     = make(chan TypedEvent, EventStreamBuffer)
+	)
+	cancel := subscribe(func(ev TypedEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed || ev == nil {
+			return
+		}
+		for {
+			select {
+			case ch <- ev:
+				return
+			default:
+			}
+			// full: drop the oldest event
+			select {
+			case <-ch:
+			default:
+			}
+		}
+	})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		cancel()
+		mu.Lock()
+		closed = true
+		close(ch)
+		mu.Unlock()
+	}()
+	return ch
+}
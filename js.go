@@ -0,0 +1,339 @@
+//go:build !wasm
+
+package youtube
+
+import (
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// JSValue is the type of raw JS values exposed by the bindings: *js.Object
+// under GopherJS and js.Value under WebAssembly
+type JSValue = *js.Object
+
+// Event is the raw argument passed to the player's event callbacks. Its Data
+// depends on the event type and can be decoded with the As* methods or Typed.
+type Event struct {
+	*js.Object
+	Target *Player    `js:"target"`
+	Data   *js.Object `js:"data"`
+}
+
+// Player represents the Youtube Iframe player
+type Player struct {
+	*js.Object
+}
+
+// Properties represents a set of video properties feeded to NewPlayer(id, properties)
+// to create the player. NewProperties() is recommended to create the properties.
+type Properties struct {
+	*js.Object
+	Width      int           `js:"width"`
+	Height     int           `js:"height"`
+	VideoID    string        `js:"videoId"`
+	PlayerVars *PlayerParams `js:"playerVars"`
+	Events     *PlayerEvents `js:"events"`
+}
+
+func newObj() *js.Object {
+	return js.Global.Get("Object").New()
+}
+
+func undefined() *js.Object {
+	return js.Undefined
+}
+
+func isNullish(o *js.Object) bool {
+	return o == js.Undefined || o == nil
+}
+
+// NewProperties creates a new Property JS object
+// with all inner objects properly initialized
+func NewProperties() *Properties {
+	props := &Properties{Object: newObj()}
+	vars := &PlayerParams{Object: newObj()}
+	eves := &PlayerEvents{Object: newObj()}
+	props.PlayerVars = vars
+	props.Events = eves
+	return props
+}
+
+// paramsJSON returns the properties without the events as JSON
+func (props *Properties) paramsJSON() string {
+	obj := js.Global.Get("Object").Call("assign", newObj(), props.Object)
+	obj.Delete("events")
+	return js.Global.Get("JSON").Call("stringify", obj).String()
+}
+
+// PlayerEvents contains a set of callbacks assigned at the creation of the
+// player. This struct's fields correspond to each youtube.EventType
+type PlayerEvents struct {
+	*js.Object
+	OnReady                 func(*Event) `js:"onReady"`
+	OnStateChange           func(*Event) `js:"onStateChange"`
+	OnPlaybackQualityChange func(*Event) `js:"onPlaybackQualityChange"`
+	OnPlaybackRateChange    func(*Event) `js:"onPlaybackRateChange"`
+	OnError                 func(*Event) `js:"onError"`
+	OnAPIChange             func(*Event) `js:"onApiChange"`
+}
+
+// PlayerParams represents the player parameter documented at
+// https://developers.google.com/youtube/player_parameters
+type PlayerParams struct {
+	*js.Object
+	Autoplay       int             `js:"autoplay"`
+	CcLoadPolicy   int             `js:"cc_load_policy"`
+	Color          ProgessBarColor `js:"color"`
+	Controls       ControlsMode    `js:"controls"`
+	DisableKB      int             `js:"disablekb"`
+	EnableJsAPI    int             `js:"enablejsapi"`
+	End            int             `js:"end"`
+	Fs             int             `js:"fs"`
+	Hl             string          `js:"hl"`
+	IvLoadPolicy   int             `js:"iv_load_policy"`
+	List           string          `js:"list"`
+	ListType       ListType        `js:"listType"`
+	Loop           int             `js:"loop"`
+	ModestBranding int             `js:"modestbranding"`
+	Origin         string          `js:"origin"`
+	Playlist       []string        `js:"playlist"`
+	PlaysInline    int             `js:"playsinline"`
+	Rel            int             `js:"rel"`
+	ShowInfo       int             `js:"showinfo"`
+	Start          int             `js:"start"`
+	WidgetReferrer string          `js:"widget_referrer"`
+}
+
+// LoadByIDOptions represents an argument for Player.LoadVideoByID2(arg)
+// and Player.CueVideoByID2(arg)
+type LoadByIDOptions struct {
+	*js.Object
+	VideoID          string  `js:"videoId"`
+	StartSeconds     float64 `js:"startSeconds"`
+	EndSeconds       float64 `js:"endSeconds"`
+	SuggestedQuality Quality `js:"suggestedQuality"`
+}
+
+// NewLoadByIDOptions prepares an argument for
+// Player.LoadVideoByID2(arg) and Player.CueVideoByID2(arg)
+// with all inner objects properly initialized
+func NewLoadByIDOptions() *LoadByIDOptions {
+	return &LoadByIDOptions{
+		Object: newObj(),
+	}
+}
+
+func (o *LoadByIDOptions) value() *js.Object {
+	return o.Object
+}
+
+// LoadByURLOptions represents an argument for Player.LoadVideoByUrl2(arg)
+type LoadByURLOptions struct {
+	*js.Object
+	MediaContentURL  string  `js:"mediaContentUrl"`
+	StartSeconds     float64 `js:"startSeconds"`
+	EndSeconds       float64 `js:"endSeconds"`
+	SuggestedQuality Quality `js:"suggestedQuality"`
+}
+
+// NewLoadByURLOptions returns a prepared argument for Player.LoadeVideoByUrl2(arg)
+// with all inner objects properly initialized
+func NewLoadByURLOptions() *LoadByURLOptions {
+	return &LoadByURLOptions{
+		Object: newObj(),
+	}
+}
+
+func (o *LoadByURLOptions) value() *js.Object {
+	return o.Object
+}
+
+// CuePlaylistOptions represents an argument for Player.CuePlaylist2(arg)
+type CuePlaylistOptions struct {
+	*js.Object
+	ListType ListType `js:"listType"`
+	// the ID of the list
+	List             string  `js:"list"`
+	Index            int     `js:"index"`
+	StartSeconds     float64 `js:"startSeconds"`
+	SuggestedQuality Quality `js:"suggestedQuality"`
+}
+
+// NewCuePlaylistOptions prepares an argument for Player.CuePlaylist2(arg)
+// with all inner objects properly initialized
+func NewCuePlaylistOptions() *CuePlaylistOptions {
+	return &CuePlaylistOptions{
+		Object: newObj(),
+	}
+}
+
+func (o *CuePlaylistOptions) value() *js.Object {
+	return o.Object
+}
+
+// NewPlayer creates a new youtube player by replacing the
+// provided iframe with the id of iframeId
+// This call is equivalent to new YT.Player(id, props)
+//
+// Commands issued before the player fires onReady are queued according to
+// its QueuePolicy.
+func NewPlayer(iframeID string, props *Properties) *Player {
+	trackReady(props)
+	np := js.Global.Get("YT").Get("Player").New(iframeID, props.Object)
+
+	p := &Player{
+		Object: np,
+	}
+	p.track()
+	return p
+}
+
+// NewEvent builds an event as the player delivers it to its callbacks. It is
+// mostly useful to test doubles.
+func NewEvent(target *Player, data interface{}) *Event {
+	e := &Event{Object: newObj()}
+	if target != nil {
+		e.Target = target
+	}
+	e.Object.Set("data", data)
+	return e
+}
+
+// newEventFunc wraps fn into a JS function called with the raw event. The
+// returned release func must be called once the function is no longer used.
+func newEventFunc(fn func(*Event)) (interface{}, func()) {
+	f := js.MakeFunc(func(this *js.Object, args []*js.Object) interface{} {
+		var e *Event
+		if len(args) > 0 {
+			e = &Event{Object: args[0]}
+		}
+		fn(e)
+		return nil
+	})
+	return f, func() {}
+}
+
+// trackReady chains the onReady callback of props so the player's Go side
+// state learns when the player becomes ready
+func trackReady(props *Properties) {
+	events := props.Get("events")
+	if isNullish(events) {
+		events = newObj()
+		props.Set("events", events)
+	}
+	prev := events.Get("onReady")
+	events.Set("onReady", js.MakeFunc(func(this *js.Object, args []*js.Object) interface{} {
+		if len(args) > 0 {
+			target := &Player{Object: args[0].Get("target")}
+			target.becomeReady()
+		}
+		if isNullish(prev) {
+			return nil
+		}
+		params := make([]interface{}, len(args))
+		for i, a := range args {
+			params[i] = a
+		}
+		return prev.Invoke(params...)
+	}))
+}
+
+// runListener runs the listener fn added with AddEventListener. Under
+// GopherJS, it runs right away.
+func (st *playerState) runListener(fn func()) {
+	fn()
+}
+
+type VideoData struct {
+	*js.Object
+	VideoID      string  `js:"video_id"`
+	Author       string  `js:"author"`
+	Title        string  `js:"title"`
+	VideoQuality Quality `js:"video_quality"`
+}
+
+// VideoData returns the data of the current video, with empty fields once the
+// player is destroyed
+func (p *Player) VideoData() *VideoData {
+	if p.destroyed() {
+		return videoDataFrom(emptyVideoData())
+	}
+	return videoDataFrom(p.Call("getVideoData"))
+}
+
+func videoDataFrom(v *js.Object) *VideoData {
+	return &VideoData{
+		Object: v,
+	}
+}
+
+// jsPanic returns the JS exception a recovered panic carries, if any
+func jsPanic(r interface{}) (cause error, kind error, ok bool) {
+	if e, isJS := r.(*js.Error); isJS {
+		return e, nil, true
+	}
+	return nil, nil, false
+}
+
+// apiLoaded reports whether YT.Player is defined
+func apiLoaded() bool {
+	yt := js.Global.Get("YT")
+	return !isNullish(yt) && !isNullish(yt.Get("Player"))
+}
+
+func isFunction(o *js.Object) bool {
+	if isNullish(o) {
+		return false
+	}
+	toString := js.Global.Get("Object").Get("prototype").Get("toString")
+	return toString.Call("call", o).String() == "[object Function]"
+}
+
+var (
+	snapshotFunc   *js.Object
+	snapshotNoEval bool
+)
+
+// callSnapshot calls the function of snapshotJS with the player, or reads
+// the getters one by one if the function cannot be built
+func callSnapshot(p *Player) string {
+	if snapshotFunc == nil && !snapshotNoEval {
+		func() {
+			// a Content-Security-Policy without unsafe-eval makes Function throw
+			defer func() {
+				if recover() != nil {
+					snapshotNoEval = true
+				}
+			}()
+			snapshotFunc = js.Global.Get("Function").New("p", snapshotJS())
+		}()
+	}
+	if snapshotNoEval {
+		return snapshotByGetters(p)
+	}
+	return snapshotFunc.Invoke(p.Object).String()
+}
+
+// snapshotByGetters builds the result of snapshotJS without eval
+func snapshotByGetters(p *Player) string {
+	r := newObj()
+	for _, g := range snapshotGetters {
+		if !isFunction(p.Get(g.method)) {
+			continue
+		}
+		func() {
+			defer func() { recover() }()
+			r.Set(g.key, p.Call(g.method))
+		}()
+	}
+	return js.Global.Get("JSON").Call("stringify", r).String()
+}
+
+// pageHidden reports whether the page is hidden, e.g. in a background tab
+func pageHidden() bool {
+	if js.Global == nil {
+		// not compiled by GopherJS, e.g. observing a ytsim player on a server
+		return false
+	}
+	doc := js.Global.Get("document")
+	return !isNullish(doc) && doc.Get("hidden").Bool()
+}
@@ -0,0 +1,554 @@
+//go:build js && wasm
+
+package youtube
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"syscall/js"
+)
+
+// JSValue is the type of raw JS values exposed by the bindings: *js.Object
+// under GopherJS and js.Value under WebAssembly
+type JSValue = js.Value
+
+// Event is the raw argument passed to the player's event callbacks. Its Data
+// depends on the event type and can be decoded with the As* methods or Typed.
+type Event struct {
+	js.Value
+	Target *Player
+	Data   js.Value
+}
+
+// Player represents the Youtube Iframe player
+type Player struct {
+	js.Value
+}
+
+// Properties represents a set of video properties feeded to NewPlayer(id, properties)
+// to create the player. NewProperties() is recommended to create the properties.
+//
+// Under WebAssembly the fields are plain Go values converted to a JS object
+// when the player is created. Width and Height work like the fields of
+// PlayerParams; properties set on the embedded js.Value are passed through as
+// is.
+type Properties struct {
+	js.Value
+	Width      int
+	Height     int
+	VideoID    string
+	PlayerVars *PlayerParams
+	Events     *PlayerEvents
+	// tracked is set by NewProperties, whose int fields start out unset
+	tracked bool
+}
+
+func newObj() js.Value {
+	return js.Global().Get("Object").New()
+}
+
+func undefined() js.Value {
+	return js.Undefined()
+}
+
+func isNullish(v js.Value) bool {
+	return v.IsUndefined() || v.IsNull()
+}
+
+// unset is the value of the int fields of NewProperties until they are
+// assigned: explicit zeros can then be told from the fields left alone.
+const unset = math.MinInt32
+
+// NewProperties creates a new Property JS object
+// with all inner objects properly initialized
+func NewProperties() *Properties {
+	return &Properties{
+		Value:      newObj(),
+		Width:      unset,
+		Height:     unset,
+		PlayerVars: newPlayerParams(),
+		Events:     &PlayerEvents{Value: newObj()},
+		tracked:    true,
+	}
+}
+
+func (props *Properties) value() (js.Value, []func()) {
+	obj := props.paramsObject()
+	events, releases := props.Events.value()
+	obj.Set("events", events)
+	return obj, releases
+}
+
+// paramsJSON returns the properties without the events as JSON
+func (props *Properties) paramsJSON() string {
+	obj := props.paramsObject()
+	obj.Delete("events")
+	return js.Global().Get("JSON").Call("stringify", obj).String()
+}
+
+func (props *Properties) paramsObject() js.Value {
+	obj := objectFrom(props.Value)
+	setParam(obj, "width", props.Width, props.tracked)
+	setParam(obj, "height", props.Height, props.tracked)
+	setString(obj, "videoId", props.VideoID)
+	if props.PlayerVars != nil {
+		obj.Set("playerVars", props.PlayerVars.value())
+	}
+	return obj
+}
+
+// PlayerEvents contains a set of callbacks assigned at the creation of the
+// player. This struct's fields correspond to each youtube.EventType
+type PlayerEvents struct {
+	js.Value
+	OnReady                 func(*Event)
+	OnStateChange           func(*Event)
+	OnPlaybackQualityChange func(*Event)
+	OnPlaybackRateChange    func(*Event)
+	OnError                 func(*Event)
+	OnAPIChange             func(*Event)
+}
+
+// value wraps the callbacks into JS functions. The callbacks are read when
+// the events fire, so they can be set after the player is created, as under
+// GopherJS. The onReady callback always lets the player's Go side state learn
+// when the player is ready.
+//
+// The callbacks run in order on a goroutine of the player rather than on the
+// JS event loop, so they may block, e.g. on the waits of the player.
+func (pe *PlayerEvents) value() (js.Value, []func()) {
+	var raw js.Value
+	if pe != nil {
+		raw = pe.Value
+	}
+	obj := objectFrom(raw)
+	var releases []func()
+	set := func(event EventType, field func(*PlayerEvents) func(*Event)) {
+		prev := obj.Get(string(event))
+		f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			var e *Event
+			if len(args) > 0 {
+				e = eventFrom(args[0])
+			}
+			if event == OnReady && e != nil && e.Target != nil {
+				e.Target.becomeReady()
+			}
+			var fn func(*Event)
+			if pe != nil {
+				fn = field(pe)
+			}
+			switch {
+			case fn != nil:
+				runCallback(e, func() { fn(e) })
+			case isFunction(prev):
+				// a callback set on the embedded js.Value
+				params := make([]interface{}, len(args))
+				for i, a := range args {
+					params[i] = a
+				}
+				prev.Invoke(params...)
+			}
+			return nil
+		})
+		obj.Set(string(event), f)
+		releases = append(releases, f.Release)
+	}
+	set(OnReady, func(pe *PlayerEvents) func(*Event) { return pe.OnReady })
+	set(OnStateChange, func(pe *PlayerEvents) func(*Event) { return pe.OnStateChange })
+	set(OnPlaybackQualityChange, func(pe *PlayerEvents) func(*Event) { return pe.OnPlaybackQualityChange })
+	set(OnPlaybackRateChange, func(pe *PlayerEvents) func(*Event) { return pe.OnPlaybackRateChange })
+	set(OnError, func(pe *PlayerEvents) func(*Event) { return pe.OnError })
+	set(OnApiChange, func(pe *PlayerEvents) func(*Event) { return pe.OnAPIChange })
+	return obj, releases
+}
+
+// runCallback runs the user callback fn of the event e on the callback queue
+// of its player, or of the page for an event without target
+func runCallback(e *Event, fn func()) {
+	if e != nil && e.Target != nil {
+		e.Target.state().callbacks.push(fn)
+		return
+	}
+	pageCallbacks.push(fn)
+}
+
+var pageCallbacks callbackQueue
+
+// runListener runs the listener fn added with AddEventListener on the
+// callback queue of the player, for the same reasons as the callbacks of
+// PlayerEvents
+func (st *playerState) runListener(fn func()) {
+	st.callbacks.push(fn)
+}
+
+// PlayerParams represents the player parameter documented at
+// https://developers.google.com/youtube/player_parameters
+//
+// The int fields of the params of NewProperties start out holding
+// math.MinInt32 and are left out until they are assigned, so an explicit zero
+// such as Controls = ControlsNotDisplay is passed as under GopherJS. The int
+// fields of params built as a struct literal are left out when zero instead.
+type PlayerParams struct {
+	js.Value
+	// tracked is set by NewProperties, whose int fields start out unset
+	tracked bool
+
+	Autoplay       int
+	CcLoadPolicy   int
+	Color          ProgessBarColor
+	Controls       ControlsMode
+	DisableKB      int
+	EnableJsAPI    int
+	End            int
+	Fs             int
+	Hl             string
+	IvLoadPolicy   int
+	List           string
+	ListType       ListType
+	Loop           int
+	ModestBranding int
+	Origin         string
+	Playlist       []string
+	PlaysInline    int
+	Rel            int
+	ShowInfo       int
+	Start          int
+	WidgetReferrer string
+}
+
+func newPlayerParams() *PlayerParams {
+	return &PlayerParams{
+		Value:          newObj(),
+		tracked:        true,
+		Autoplay:       unset,
+		CcLoadPolicy:   unset,
+		Controls:       unset,
+		DisableKB:      unset,
+		EnableJsAPI:    unset,
+		End:            unset,
+		Fs:             unset,
+		IvLoadPolicy:   unset,
+		Loop:           unset,
+		ModestBranding: unset,
+		PlaysInline:    unset,
+		Rel:            unset,
+		ShowInfo:       unset,
+		Start:          unset,
+	}
+}
+
+func (pp *PlayerParams) value() js.Value {
+	obj := objectFrom(pp.Value)
+	param := func(key string, v int) { setParam(obj, key, v, pp.tracked) }
+	param("autoplay", pp.Autoplay)
+	param("cc_load_policy", pp.CcLoadPolicy)
+	setString(obj, "color", string(pp.Color))
+	param("controls", int(pp.Controls))
+	param("disablekb", pp.DisableKB)
+	param("enablejsapi", pp.EnableJsAPI)
+	param("end", pp.End)
+	param("fs", pp.Fs)
+	setString(obj, "hl", pp.Hl)
+	param("iv_load_policy", pp.IvLoadPolicy)
+	setString(obj, "list", pp.List)
+	setString(obj, "listType", string(pp.ListType))
+	param("loop", pp.Loop)
+	param("modestbranding", pp.ModestBranding)
+	setString(obj, "origin", pp.Origin)
+	if len(pp.Playlist) > 0 {
+		obj.Set("playlist", stringArray(pp.Playlist))
+	}
+	param("playsinline", pp.PlaysInline)
+	param("rel", pp.Rel)
+	param("showinfo", pp.ShowInfo)
+	param("start", pp.Start)
+	setString(obj, "widget_referrer", pp.WidgetReferrer)
+	return obj
+}
+
+// LoadByIDOptions represents an argument for Player.LoadVideoByID2(arg)
+// and Player.CueVideoByID2(arg)
+type LoadByIDOptions struct {
+	js.Value
+	VideoID          string
+	StartSeconds     float64
+	EndSeconds       float64
+	SuggestedQuality Quality
+}
+
+// NewLoadByIDOptions prepares an argument for
+// Player.LoadVideoByID2(arg) and Player.CueVideoByID2(arg)
+// with all inner objects properly initialized
+func NewLoadByIDOptions() *LoadByIDOptions {
+	return &LoadByIDOptions{
+		Value: newObj(),
+	}
+}
+
+func (o *LoadByIDOptions) value() js.Value {
+	obj := objectFrom(o.Value)
+	setString(obj, "videoId", o.VideoID)
+	setFloat(obj, "startSeconds", o.StartSeconds)
+	setFloat(obj, "endSeconds", o.EndSeconds)
+	setString(obj, "suggestedQuality", string(o.SuggestedQuality))
+	return obj
+}
+
+// LoadByURLOptions represents an argument for Player.LoadVideoByUrl2(arg)
+type LoadByURLOptions struct {
+	js.Value
+	MediaContentURL  string
+	StartSeconds     float64
+	EndSeconds       float64
+	SuggestedQuality Quality
+}
+
+// NewLoadByURLOptions returns a prepared argument for Player.LoadeVideoByUrl2(arg)
+// with all inner objects properly initialized
+func NewLoadByURLOptions() *LoadByURLOptions {
+	return &LoadByURLOptions{
+		Value: newObj(),
+	}
+}
+
+func (o *LoadByURLOptions) value() js.Value {
+	obj := objectFrom(o.Value)
+	setString(obj, "mediaContentUrl", o.MediaContentURL)
+	setFloat(obj, "startSeconds", o.StartSeconds)
+	setFloat(obj, "endSeconds", o.EndSeconds)
+	setString(obj, "suggestedQuality", string(o.SuggestedQuality))
+	return obj
+}
+
+// CuePlaylistOptions represents an argument for Player.CuePlaylist2(arg)
+type CuePlaylistOptions struct {
+	js.Value
+	ListType ListType
+	// the ID of the list
+	List             string
+	Index            int
+	StartSeconds     float64
+	SuggestedQuality Quality
+}
+
+// NewCuePlaylistOptions prepares an argument for Player.CuePlaylist2(arg)
+// with all inner objects properly initialized
+func NewCuePlaylistOptions() *CuePlaylistOptions {
+	return &CuePlaylistOptions{
+		Value: newObj(),
+	}
+}
+
+func (o *CuePlaylistOptions) value() js.Value {
+	obj := objectFrom(o.Value)
+	setString(obj, "listType", string(o.ListType))
+	setString(obj, "list", o.List)
+	setInt(obj, "index", o.Index)
+	setFloat(obj, "startSeconds", o.StartSeconds)
+	setString(obj, "suggestedQuality", string(o.SuggestedQuality))
+	return obj
+}
+
+// NewPlayer creates a new youtube player by replacing the
+// provided iframe with the id of iframeId
+// This call is equivalent to new YT.Player(id, props)
+//
+// Commands issued before the player fires onReady are queued according to
+// its QueuePolicy.
+func NewPlayer(iframeID string, props *Properties) *Player {
+	obj, releases := props.value()
+	np := js.Global().Get("YT").Get("Player").New(iframeID, obj)
+
+	p := &Player{
+		Value: np,
+	}
+	p.track()
+	st := p.state()
+	for _, release := range releases {
+		st.addRelease(release)
+	}
+	return p
+}
+
+// NewEvent builds an event as the player delivers it to its callbacks. It is
+// mostly useful to test doubles.
+func NewEvent(target *Player, data interface{}) *Event {
+	obj := newObj()
+	if target != nil {
+		obj.Set("target", target.Value)
+	}
+	obj.Set("data", data)
+	return &Event{Value: obj, Target: target, Data: obj.Get("data")}
+}
+
+// newEventFunc wraps fn into a JS function called with the raw event. The
+// returned release func must be called once the function is no longer used.
+func newEventFunc(fn func(*Event)) (interface{}, func()) {
+	f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		var e *Event
+		if len(args) > 0 {
+			e = eventFrom(args[0])
+		}
+		fn(e)
+		return nil
+	})
+	return f, f.Release
+}
+
+func eventFrom(v js.Value) *Event {
+	if v.Type() != js.TypeObject {
+		return &Event{Value: v, Data: js.Undefined()}
+	}
+	e := &Event{Value: v, Data: v.Get("data")}
+	if target := v.Get("target"); !isNullish(target) {
+		e.Target = &Player{Value: target}
+	}
+	return e
+}
+
+type VideoData struct {
+	js.Value
+	VideoID      string
+	Author       string
+	Title        string
+	VideoQuality Quality
+}
+
+// VideoData returns the data of the current video, with empty fields once the
+// player is destroyed
+func (p *Player) VideoData() *VideoData {
+	if p.destroyed() {
+		return videoDataFrom(emptyVideoData())
+	}
+	return videoDataFrom(p.Call("getVideoData"))
+}
+
+func videoDataFrom(v js.Value) *VideoData {
+	return &VideoData{
+		Value:        v,
+		VideoID:      stringProp(v, "video_id"),
+		Author:       stringProp(v, "author"),
+		Title:        stringProp(v, "title"),
+		VideoQuality: Quality(stringProp(v, "video_quality")),
+	}
+}
+
+// jsPanic returns the JS exception a recovered panic carries, if any.
+// Decoding an undefined result panics with a *js.ValueError, and calling a
+// missing method with a string.
+func jsPanic(r interface{}) (cause error, kind error, ok bool) {
+	switch e := r.(type) {
+	case js.Error:
+		return e, nil, true
+	case *js.ValueError:
+		return e, ErrUndefined, true
+	case string:
+		if strings.HasPrefix(e, "syscall/js: ") {
+			return errors.New(e), nil, true
+		}
+	}
+	return nil, nil, false
+}
+
+// apiLoaded reports whether YT.Player is defined
+func apiLoaded() bool {
+	yt := js.Global().Get("YT")
+	return !isNullish(yt) && !isNullish(yt.Get("Player"))
+}
+
+func isFunction(v js.Value) bool {
+	return v.Type() == js.TypeFunction
+}
+
+// objectFrom returns a new JS object holding a copy of the properties of raw
+func objectFrom(raw js.Value) js.Value {
+	obj := newObj()
+	if raw.Type() == js.TypeObject {
+		js.Global().Get("Object").Call("assign", obj, raw)
+	}
+	return obj
+}
+
+func stringProp(v js.Value, key string) string {
+	if v.Type() != js.TypeObject {
+		return ""
+	}
+	if prop := v.Get(key); prop.Type() == js.TypeString {
+		return prop.String()
+	}
+	return ""
+}
+
+// setParam sets the int field v under key unless it is unset: never assigned
+// if the field is tracked, or else zero
+func setParam(obj js.Value, key string, v int, tracked bool) {
+	if v == unset || (v == 0 && !tracked) {
+		return
+	}
+	obj.Set(key, v)
+}
+
+func setInt(obj js.Value, key string, v int) {
+	if v != 0 {
+		obj.Set(key, v)
+	}
+}
+
+func setFloat(obj js.Value, key string, v float64) {
+	if v != 0 {
+		obj.Set(key, v)
+	}
+}
+
+func setString(obj js.Value, key, v string) {
+	if v != "" {
+		obj.Set(key, v)
+	}
+}
+
+var (
+	snapshotFunc   js.Value
+	snapshotNoEval bool
+)
+
+// callSnapshot calls the function of snapshotJS with the player, or reads
+// the getters one by one if the function cannot be built
+func callSnapshot(p *Player) string {
+	if snapshotFunc.IsUndefined() && !snapshotNoEval {
+		func() {
+			// a Content-Security-Policy without unsafe-eval makes Function throw
+			defer func() {
+				if recover() != nil {
+					snapshotNoEval = true
+				}
+			}()
+			snapshotFunc = js.Global().Get("Function").New("p", snapshotJS())
+		}()
+	}
+	if snapshotNoEval {
+		return snapshotByGetters(p)
+	}
+	return snapshotFunc.Invoke(p.Value).String()
+}
+
+// snapshotByGetters builds the result of snapshotJS without eval
+func snapshotByGetters(p *Player) string {
+	r := newObj()
+	for _, g := range snapshotGetters {
+		if !isFunction(p.Get(g.method)) {
+			continue
+		}
+		func() {
+			defer func() { recover() }()
+			r.Set(g.key, p.Call(g.method))
+		}()
+	}
+	return js.Global().Get("JSON").Call("stringify", r).String()
+}
+
+// pageHidden reports whether the page is hidden, e.g. in a background tab
+func pageHidden() bool {
+	doc := js.Global().Get("document")
+	return !isNullish(doc) && doc.Get("hidden").Truthy()
+}
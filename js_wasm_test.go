@@ -0,0 +1,170 @@
+//go:build js && wasm
+
+package youtube_test
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube"
+)
+
+// fakeYT is a minimal YT.Player recording what the bindings pass it. fire
+// delivers an event to the callback of the properties and to the listeners.
+const fakeYT = `globalThis.YT = {Player: function(id, cfg) {
+	var self = this;
+	self.id = id;
+	self.cfg = cfg;
+	self.calls = [];
+	self.listeners = {};
+	self.state = -1;
+	["playVideo", "pauseVideo"].forEach(function(name) {
+		self[name] = function() { self.calls.push(name); };
+	});
+	self.getPlayerState = function() { return self.state; };
+	self.getIframe = function() { return {isConnected: true}; };
+	self.addEventListener = function(event, fn) {
+		(self.listeners[event] = self.listeners[event] || []).push(fn);
+	};
+	self.removeEventListener = function(event, fn) {
+		var l = self.listeners[event] || [];
+		var i = l.indexOf(fn);
+		if (i >= 0) l.splice(i, 1);
+	};
+	self.destroy = function() { self.destroyed = true; };
+	self.fire = function(event, data) {
+		var e = {target: self, data: data};
+		if (cfg.events && cfg.events[event]) cfg.events[event](e);
+		(self.listeners[event] || []).slice().forEach(function(fn) { fn(e); });
+	};
+	globalThis.ytLast = self;
+}};`
+
+// newFakePlayer installs fakeYT and creates a player with props
+func newFakePlayer(t *testing.T, props *youtube.Properties) (*youtube.Player, js.Value) {
+	t.Helper()
+	js.Global().Call("eval", fakeYT)
+	p := youtube.NewPlayer("player", props)
+	fake := js.Global().Get("ytLast")
+	if fake.Get("id").String() != "player" {
+		t.Fatalf("fake player created for %v", fake.Get("id"))
+	}
+	return p, fake
+}
+
+func receive(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestWasmPropertiesPassExplicitZeros(t *testing.T) {
+	props := youtube.NewProperties()
+	props.VideoID = "dQw4w9WgXcQ"
+	props.PlayerVars.Controls = youtube.ControlsNotDisplay
+	props.PlayerVars.Start = 0
+	_, fake := newFakePlayer(t, props)
+
+	cfg := fake.Get("cfg")
+	if got := cfg.Get("videoId").String(); got != "dQw4w9WgXcQ" {
+		t.Errorf("videoId = %q", got)
+	}
+	vars := cfg.Get("playerVars")
+	for _, key := range []string{"controls", "start"} {
+		if v := vars.Get(key); v.Type() != js.TypeNumber || v.Int() != 0 {
+			t.Errorf("%s = %v, want an explicit 0", key, v)
+		}
+	}
+	for _, key := range []string{"autoplay", "end", "loop"} {
+		if v := vars.Get(key); !v.IsUndefined() {
+			t.Errorf("%s = %v, want it left out", key, v)
+		}
+	}
+	if v := cfg.Get("width"); !v.IsUndefined() {
+		t.Errorf("width = %v, want it left out", v)
+	}
+}
+
+func TestWasmCommandsAreQueuedUntilReady(t *testing.T) {
+	props := youtube.NewProperties()
+	ready := make(chan struct{})
+	props.Events.OnReady = func(*youtube.Event) { close(ready) }
+	p, fake := newFakePlayer(t, props)
+
+	p.PlayVideo()
+	if n := fake.Get("calls").Length(); n != 0 {
+		t.Fatalf("%d calls before onReady", n)
+	}
+	fake.Call("fire", string(youtube.OnReady))
+	receive(t, ready, "the onReady callback")
+	if p.Lifecycle() != youtube.LifecycleReady {
+		t.Errorf("lifecycle = %v, want ready", p.Lifecycle())
+	}
+	if calls := fake.Get("calls"); calls.Length() != 1 || calls.Index(0).String() != "playVideo" {
+		t.Errorf("calls after onReady = %v, want playVideo", js.Global().Get("JSON").Call("stringify", calls))
+	}
+}
+
+func TestWasmListenerRemove(t *testing.T) {
+	p, fake := newFakePlayer(t, youtube.NewProperties())
+	fake.Call("fire", string(youtube.OnReady))
+
+	called := make(chan struct{}, 10)
+	l := p.AddEventListener(youtube.OnStateChange, func(e *youtube.Event) {
+		if e.AsStateChange().State == youtube.Playing {
+			called <- struct{}{}
+		}
+	})
+	fake.Call("fire", string(youtube.OnStateChange), int(youtube.Playing))
+	receive(t, called, "the listener")
+
+	l.Remove()
+	if n := fake.Get("listeners").Get(string(youtube.OnStateChange)).Length(); n != 0 {
+		t.Fatalf("%d JS listeners left after Remove", n)
+	}
+	fake.Call("fire", string(youtube.OnStateChange), int(youtube.Playing))
+	select {
+	case <-called:
+		t.Error("listener called after Remove")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestWasmDestroyReleasesTheCallbacks(t *testing.T) {
+	props := youtube.NewProperties()
+	props.Events.OnStateChange = func(*youtube.Event) {}
+	p, fake := newFakePlayer(t, props)
+	fake.Call("fire", string(youtube.OnReady))
+	events := p.Events(context.Background(), youtube.OnStateChange)
+
+	p.Destroy()
+	if !fake.Get("destroyed").Truthy() {
+		t.Error("destroy was not called on the JS player")
+	}
+	if p.Lifecycle() != youtube.LifecycleDestroyed || p.PlayerState() != youtube.Unstarted {
+		t.Errorf("after Destroy: lifecycle %v, state %v", p.Lifecycle(), p.PlayerState())
+	}
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("event received after Destroy")
+		}
+	case <-time.After(time.Second):
+		t.Error("event stream not closed by Destroy")
+	}
+
+	// calling a released Go function only logs an error
+	js.Global().Call("eval", `globalThis.ytErrors = [];
+		globalThis.ytConsoleError = console.error;
+		console.error = function(msg) { ytErrors.push(String(msg)); };`)
+	defer js.Global().Call("eval", `console.error = ytConsoleError;`)
+	fake.Get("cfg").Get("events").Call(string(youtube.OnStateChange), map[string]interface{}{"data": 1})
+	if errs := js.Global().Get("ytErrors"); errs.Length() != 1 || errs.Index(0).String() != "call to released function" {
+		t.Errorf("the onStateChange callback was not released: %v", js.Global().Get("JSON").Call("stringify", errs))
+	}
+}
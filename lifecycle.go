@@ -0,0 +1,61 @@
+package youtube
+
+import "strconv"
+
+// Lifecycle is the stage of a player's life
+type Lifecycle int
+
+const (
+	// LifecycleCreating is the stage of a player waiting for onReady
+	LifecycleCreating Lifecycle = iota
+	// LifecycleReady is the stage of a player that fired onReady
+	LifecycleReady
+	// LifecycleDestroyed is the stage of a destroyed player. Its commands
+	// are ignored and its listeners are released.
+	LifecycleDestroyed
+)
+
+func (l Lifecycle) String() string {
+	switch l {
+	case LifecycleCreating:
+		return "creating"
+	case LifecycleReady:
+		return "ready"
+	case LifecycleDestroyed:
+		return "destroyed"
+	default:
+		return "Lifecycle(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
+// Lifecycle returns the stage of the player's life
+func (p *Player) Lifecycle() Lifecycle {
+	return p.state().Lifecycle()
+}
+
+// OnDestroy registers fn to be called once the player is destroyed, after
+// its listeners are released. fn is called right away if the player is
+// already destroyed. The returned func unregisters fn.
+func (p *Player) OnDestroy(fn func()) (remove func()) {
+	return p.state().OnDestroy(fn)
+}
+
+// Destroy removes the iframe containing the player. Pending waits on the
+// player return ErrDestroyed, event streams are closed, every listener is
+// released and the OnDestroy hooks are called. Later commands are ignored,
+// getters return zero values, and calling Destroy again does nothing.
+//
+// A player whose iframe is removed from the document without Destroy is
+// released the same way, without the JS call, by SweepDetached.
+func (p *Player) Destroy() {
+	st := p.state()
+	st.Destroy(func() {
+		st.dropQueue()
+		defer func() {
+			st.releaseListeners()
+			st.releaseFuncs()
+			p.forget()
+		}()
+		p.Call("destroy")
+	})
+}
@@ -0,0 +1,182 @@
+package youtube
+
+import "sync"
+
+// Listener is a handle of an event listener registered with
+// Player.AddEventListener
+type Listener interface {
+	// Remove unsubscribes the listener. It is safe to call Remove more
+	// than once.
+	Remove()
+}
+
+type listener struct {
+	once   sync.Once
+	event  EventType
+	fn     func(*Event)
+	remove func()
+	// internal is set for the listeners of the bindings, such as those of
+	// the event streams, which never block
+	internal bool
+}
+
+func (l *listener) Remove() {
+	l.once.Do(l.remove)
+}
+
+// dispatcher is the single JS function registered with the player for an
+// event type. It fans the event out to every Go listener of that type.
+type dispatcher struct {
+	fn        interface{}
+	release   func()
+	listeners []*listener
+}
+
+// AddEventListener adds a listener for the given event. Any number of
+// listeners can be added for the same event; they are called in the order
+// they were added. The returned handle unsubscribes the listener. Listeners
+// are released when the player is destroyed, and adding one afterwards does
+// nothing.
+//
+// Under WebAssembly, listeners run in order on a goroutine of the player
+// rather than on the JS event loop, so they may block, e.g. on the waits of
+// the player.
+func (p *Player) AddEventListener(event EventType, fn func(event *Event)) Listener {
+	return p.addListener(event, fn, false)
+}
+
+func (p *Player) addListener(event EventType, fn func(event *Event), internal bool) Listener {
+	st := p.state()
+	l := &listener{event: event, fn: fn, internal: internal}
+	l.remove = func() { st.removeListener(p, l) }
+
+	st.mu.Lock()
+	if st.isDestroyed() {
+		// the listener would never be called
+		st.mu.Unlock()
+		l.fn = nil
+		l.remove = func() {}
+		return l
+	}
+	d, ok := st.dispatchers[event]
+	if !ok {
+		d = &dispatcher{}
+		d.fn, d.release = newEventFunc(func(e *Event) {
+			st.mu.Lock()
+			ls := append([]*listener(nil), d.listeners...)
+			st.mu.Unlock()
+			for _, l := range ls {
+				l := l
+				call := func() {
+					// a listener may remove another one while dispatching
+					if fn := l.fn; fn != nil {
+						fn(e)
+					}
+				}
+				if l.internal {
+					call()
+				} else {
+					st.runListener(call)
+				}
+			}
+		})
+		st.dispatchers[event] = d
+	}
+	d.listeners = append(d.listeners, l)
+	st.mu.Unlock()
+
+	if !ok {
+		p.Call("addEventListener", string(event), d.fn)
+	}
+	return l
+}
+
+// RemoveEventListener removes a listener previously added with
+// AddEventListener. It is equivalent to l.Remove().
+func (p *Player) RemoveEventListener(l Listener) {
+	if l != nil {
+		l.Remove()
+	}
+}
+
+// removeListener drops l from its dispatcher. The dispatcher itself is
+// unregistered from the player and released once it has no listeners left.
+func (st *playerState) removeListener(p *Player, l *listener) {
+	st.mu.Lock()
+	d, ok := st.dispatchers[l.event]
+	if !ok {
+		st.mu.Unlock()
+		return
+	}
+	for i, o := range d.listeners {
+		if o == l {
+			d.listeners = append(d.listeners[:i:i], d.listeners[i+1:]...)
+			break
+		}
+	}
+	empty := len(d.listeners) == 0
+	if empty {
+		delete(st.dispatchers, l.event)
+	}
+	st.mu.Unlock()
+
+	l.fn = nil
+	if empty {
+		p.Call("removeEventListener", string(l.event), d.fn)
+		d.release()
+		d.fn = nil
+	}
+}
+
+// releaseListeners drops every listener of a destroyed player and releases
+// the dispatchers. The JS player is gone, so they are not unregistered from
+// it.
+func (st *playerState) releaseListeners() {
+	st.mu.Lock()
+	dispatchers := st.dispatchers
+	st.dispatchers = make(map[EventType]*dispatcher)
+	st.mu.Unlock()
+	for _, d := range dispatchers {
+		for _, l := range d.listeners {
+			l.once.Do(func() {})
+			l.fn = nil
+		}
+		d.release()
+		d.fn = nil
+	}
+}
+
+// callbackQueue runs functions one at a time, in the order they are pushed,
+// on a goroutine started whenever there is something to run
+type callbackQueue struct {
+	mu      sync.Mutex
+	fns     []func()
+	running bool
+}
+
+func (q *callbackQueue) push(fn func()) {
+	q.mu.Lock()
+	q.fns = append(q.fns, fn)
+	if q.running {
+		q.mu.Unlock()
+		return
+	}
+	q.running = true
+	q.mu.Unlock()
+	go q.run()
+}
+
+func (q *callbackQueue) run() {
+	for {
+		q.mu.Lock()
+		if len(q.fns) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		fn := q.fns[0]
+		q.fns = q.fns[1:]
+		q.mu.Unlock()
+		fn()
+	}
+}
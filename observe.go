@@ -0,0 +1,150 @@
+package youtube
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultObserveInterval is the polling interval of Observe when the given
+// one is not positive
+const DefaultObserveInterval = 250 * time.Millisecond
+
+// The event types of the synthetic events of Observe. The player has no such
+// events: they cannot be passed to AddEventListener.
+const (
+	OnTimeUpdate          EventType = "onTimeUpdate"
+	OnProgress            EventType = "onProgress"
+	OnVolumeChange        EventType = "onVolumeChange"
+	OnPlaylistIndexChange EventType = "onPlaylistIndexChange"
+)
+
+// TimeUpdateEvent is emitted by Observe when the elapsed time changes
+type TimeUpdateEvent struct {
+	Position time.Duration
+}
+
+// ProgressEvent is emitted by Observe when more of the video is buffered
+type ProgressEvent struct {
+	LoadedFraction float64
+}
+
+// VolumeChangeEvent is emitted by Observe when the volume changes or the
+// player is muted or unmuted
+type VolumeChangeEvent struct {
+	Volume int
+	Muted  bool
+}
+
+// PlaylistIndexChangeEvent is emitted by Observe when the player moves to
+// another video of the playlist. Index is -1 when the playlist is gone.
+type PlaylistIndexChangeEvent struct {
+	Index int
+}
+
+func (TimeUpdateEvent) EventType() EventType          { return OnTimeUpdate }
+func (ProgressEvent) EventType() EventType            { return OnProgress }
+func (VolumeChangeEvent) EventType() EventType        { return OnVolumeChange }
+func (PlaylistIndexChangeEvent) EventType() EventType { return OnPlaylistIndexChange }
+
+// Observe polls the player every interval and calls fn with synthetic events
+// for the values the player has no events for. See Observe.
+func (p *Player) Observe(interval time.Duration, fn func(TypedEvent)) (stop func()) {
+	return Observe(p, interval, fn)
+}
+
+// Observe polls the status of c every interval, DefaultObserveInterval if it
+// is not positive, with Snapshot, and calls fn
+// with a TimeUpdateEvent, ProgressEvent, VolumeChangeEvent, RateChangeEvent
+// or PlaylistIndexChangeEvent for each value that changed since the previous
+// poll, in this order. fn is called from a goroutine of its own.
+//
+// Polling pauses while the player is not playing or buffering, and while the
+// page is hidden. The status is still polled once on every state change, so
+// the position a video is paused at is reported.
+//
+// Observing stops when stop is called or the player is destroyed.
+func Observe(c Controller, interval time.Duration, fn func(TypedEvent)) (stop func()) {
+	var (
+		prev  PlayerStatus
+		first = true
+	)
+	if interval <= 0 {
+		interval = DefaultObserveInterval
+	}
+	return startPolling(c, interval, nil, func(s PlayerStatus) {
+		if !first {
+			emitChanges(s, prev, fn)
+		}
+		prev, first = s, false
+	})
+}
+
+// startPolling calls fn from a goroutine with the status of c: right away,
+// then every interval while c is playing or buffering and the page is
+// visible, once on every state change, and whenever wake receives. interval
+// must be positive. Polling stops when stop is called or c is destroyed.
+func startPolling(c Controller, interval time.Duration, wake <-chan struct{}, fn func(PlayerStatus)) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	states := c.Events(ctx, OnStateChange)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s := Snapshot(c)
+		fn(s)
+		active := polling(s.State)
+		for {
+			var tick <-chan time.Time
+			if active {
+				tick = ticker.C
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-states:
+				if !ok {
+					// destroyed
+					return
+				}
+				if sc, ok := ev.(StateChangeEvent); ok {
+					active = polling(sc.State)
+				}
+			case <-wake:
+			case <-tick:
+				if pageHidden() {
+					continue
+				}
+			}
+			if ctx.Err() != nil || c.Lifecycle() == LifecycleDestroyed {
+				return
+			}
+			fn(Snapshot(c))
+		}
+	}()
+	return cancel
+}
+
+func polling(state PlayerState) bool {
+	return state == Playing || state == Buffering
+}
+
+// emitChanges calls fn with the events for the observed values that changed
+// from prev to s
+func emitChanges(s, prev PlayerStatus, fn func(TypedEvent)) {
+	changed := s.Diff(prev)
+	if changed.Has(StatusPosition) {
+		fn(TimeUpdateEvent{Position: s.Position})
+	}
+	if changed.Has(StatusLoadedFraction) {
+		fn(ProgressEvent{LoadedFraction: s.LoadedFraction})
+	}
+	if changed&(StatusVolume|StatusMuted) != 0 {
+		fn(VolumeChangeEvent{Volume: s.Volume, Muted: s.Muted})
+	}
+	if changed.Has(StatusPlaybackRate) {
+		fn(RateChangeEvent{Rate: s.PlaybackRate})
+	}
+	if changed.Has(StatusPlaylistIndex) {
+		fn(PlaylistIndexChangeEvent{Index: s.PlaylistIndex})
+	}
+}
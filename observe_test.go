@@ -0,0 +1,149 @@
+package youtube_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/ytsim"
+)
+
+func TestObserveWithoutIntervalUsesTheDefault(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+
+	updates := make(chan youtube.TimeUpdateEvent, 10)
+	stop := youtube.Observe(p, 0, func(ev youtube.TypedEvent) {
+		if tu, ok := ev.(youtube.TimeUpdateEvent); ok {
+			updates <- tu
+		}
+	})
+	defer stop()
+	// the first poll may come after any advance: keep playing until one shows
+	deadline := time.After(10 * youtube.DefaultObserveInterval)
+	for {
+		p.Advance(time.Second)
+		select {
+		case tu := <-updates:
+			if tu.Position <= 0 {
+				t.Errorf("position = %v, want it past the start", tu.Position)
+			}
+			return
+		case <-time.After(youtube.DefaultObserveInterval / 5):
+		case <-deadline:
+			t.Fatal("no time update")
+		}
+	}
+}
+
+const observeInterval = 10 * time.Millisecond
+
+// observe starts observing p, sending the events to the returned channel
+func observe(p youtube.Controller) (<-chan youtube.TypedEvent, func()) {
+	events := make(chan youtube.TypedEvent, 100)
+	stop := youtube.Observe(p, observeInterval, func(ev youtube.TypedEvent) { events <- ev })
+	// let the first poll take the initial status
+	time.Sleep(3 * observeInterval)
+	return events, stop
+}
+
+func next(t *testing.T, events <-chan youtube.TypedEvent) youtube.TypedEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+		return nil
+	}
+}
+
+// quiet checks that no event comes for a few intervals
+func quiet(t *testing.T, events <-chan youtube.TypedEvent, when string) {
+	t.Helper()
+	select {
+	case ev := <-events:
+		t.Errorf("%s: unexpected %T %+v", when, ev, ev)
+	case <-time.After(5 * observeInterval):
+	}
+}
+
+func TestObserveEmitsChanges(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadPlaylist([]string{"dQw4w9WgXcQ", "9bZkp7q19f0"}, 0, 0, youtube.Auto)
+	events, stop := observe(p)
+	defer stop()
+	quiet(t, events, "nothing changed")
+
+	p.SetVolume(30)
+	if ev := next(t, events); ev != (youtube.VolumeChangeEvent{Volume: 30}) {
+		t.Errorf("after SetVolume: %+v", ev)
+	}
+	p.Mute()
+	if ev := next(t, events); ev != (youtube.VolumeChangeEvent{Volume: 30, Muted: true}) {
+		t.Errorf("after Mute: %+v", ev)
+	}
+	p.SetPlaybackRate(1.5)
+	if ev := next(t, events); ev != (youtube.RateChangeEvent{Rate: 1.5}) {
+		t.Errorf("after SetPlaybackRate: %+v", ev)
+	}
+	// the buffer moves along with the position, reported in the same poll
+	p.Advance(time.Second)
+	if ev := next(t, events); ev != (youtube.TimeUpdateEvent{Position: 1500 * time.Millisecond}) {
+		t.Errorf("first event after Advance: %+v", ev)
+	}
+	if ev, ok := next(t, events).(youtube.ProgressEvent); !ok || ev.LoadedFraction <= 0 {
+		t.Errorf("second event after Advance: %+v", ev)
+	}
+	quiet(t, events, "nothing changed since")
+
+	p.NextVideo()
+	if ev := next(t, events); ev != (youtube.TimeUpdateEvent{}) {
+		t.Errorf("first event after NextVideo: %+v", ev)
+	}
+	if _, ok := next(t, events).(youtube.ProgressEvent); !ok {
+		t.Error("no progress event after NextVideo")
+	}
+	if ev := next(t, events); ev != (youtube.PlaylistIndexChangeEvent{Index: 1}) {
+		t.Errorf("third event after NextVideo: %+v", ev)
+	}
+}
+
+func TestObserveIsQuietWhilePaused(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	events, stop := observe(p)
+	defer stop()
+
+	p.PauseVideo()
+	quiet(t, events, "paused")
+	p.SetVolume(10)
+	quiet(t, events, "volume changed while paused")
+
+	// the state change polls again
+	p.PlayVideo()
+	if ev := next(t, events); ev != (youtube.VolumeChangeEvent{Volume: 10}) {
+		t.Errorf("after PlayVideo: %+v", ev)
+	}
+}
+
+func TestObserveStop(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	events, stop := observe(p)
+
+	stop()
+	// a poll may have been running when stop was called
+	time.Sleep(3 * observeInterval)
+	for len(events) > 0 {
+		<-events
+	}
+	p.SetVolume(10)
+	p.Advance(time.Second)
+	p.PauseVideo()
+	quiet(t, events, "stopped")
+}
@@ -0,0 +1,367 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iocat/youtube/yttype"
+	"github.com/iocat/youtube/yturl"
+)
+
+// ErrInvalidOption is wrapped by the errors New returns for invalid options
+var ErrInvalidOption = errors.New("youtube: invalid option")
+
+// Option configures the player created by New
+type Option func(*config) error
+
+// config collects the options of New before the properties are built
+type config struct {
+	width, height int
+	videoID       string
+	vars          map[string]interface{}
+	playlist      []string
+	list          string
+	listType      ListType
+	start, end    time.Duration
+	loop          bool
+	handlers      map[EventType][]func(*Event)
+	queuePolicy   QueuePolicy
+}
+
+// New creates a player replacing the element with the id elementID, like
+// NewPlayer, from typed options:
+//
+//	p, err := youtube.New("player",
+//		youtube.WithVideo("dQw4w9WgXcQ"),
+//		youtube.WithAutoplay(),
+//		youtube.WithStart(90*time.Second),
+//		youtube.WithOnReady(func(p *youtube.Player) { p.PlayVideo() }),
+//	)
+//
+// The options, then the resulting properties, are validated before the player
+// is created. New returns ErrAPIMissing if the Iframe API is not loaded yet.
+func New(elementID string, opts ...Option) (*Player, error) {
+	c := &config{vars: make(map[string]interface{}), handlers: make(map[EventType][]func(*Event))}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	props := c.properties()
+	if err := props.Validate(); err != nil {
+		return nil, err
+	}
+	p, err := TryNewPlayer(elementID, props)
+	if err != nil {
+		return nil, err
+	}
+	p.SetQueuePolicy(c.queuePolicy)
+	return p, nil
+}
+
+func invalidOption(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidOption, fmt.Sprintf(format, args...))
+}
+
+// validate checks the combinations of options
+func (c *config) validate() error {
+	if c.end > 0 && c.end <= c.start {
+		return invalidOption("end %v is not after start %v", c.end, c.start)
+	}
+	if c.loop && c.videoID == "" && len(c.playlist) == 0 && c.list == "" {
+		return invalidOption("loop needs a video or a playlist")
+	}
+	if c.listType != "" && c.list == "" {
+		return invalidOption("list type %q needs a list", c.listType)
+	}
+	if len(c.playlist) > 0 && c.list != "" {
+		return invalidOption("a playlist of video IDs and a list cannot be both set")
+	}
+	return nil
+}
+
+// properties builds the properties of the player. Parameters are set on the
+// embedded JS objects so explicit zeros, such as controls=0, are kept by both
+// backends.
+func (c *config) properties() *Properties {
+	props := NewProperties()
+	if c.width > 0 {
+		props.Width = c.width
+		props.Height = c.height
+	}
+	if c.videoID != "" {
+		props.VideoID = c.videoID
+	}
+
+	vars := props.PlayerVars
+	for key, v := range c.vars {
+		vars.Set(key, v)
+	}
+	start, end := yttype.RangeSeconds(c.start, c.end)
+	if start > 0 {
+		vars.Set("start", start)
+	}
+	if end > 0 {
+		vars.Set("end", end)
+	}
+	playlist := c.playlist
+	if c.loop {
+		vars.Set("loop", 1)
+		if len(playlist) == 0 && c.list == "" {
+			// a single video only loops as a playlist of itself
+			playlist = []string{c.videoID}
+		}
+	}
+	if len(playlist) > 0 {
+		vars.Set("playlist", stringArray(playlist))
+	}
+	if c.list != "" {
+		vars.Set("list", c.list)
+		listType := c.listType
+		if listType == "" {
+			listType = ListTypePlaylist
+		}
+		vars.Set("listType", string(listType))
+	}
+
+	for event, handlers := range c.handlers {
+		handlers := handlers
+		props.Events.set(event, func(e *Event) {
+			for _, h := range handlers {
+				h(e)
+			}
+		})
+	}
+	return props
+}
+
+func setVar(key string, value interface{}) Option {
+	return func(c *config) error {
+		c.vars[key] = value
+		return nil
+	}
+}
+
+// WithVideo loads the video with the given ID
+func WithVideo(id string) Option {
+	return func(c *config) error {
+		if !yturl.ValidVideoID(id) {
+			return invalidOption("video ID %q", id)
+		}
+		c.videoID = id
+		return nil
+	}
+}
+
+// WithPlaylist plays the videos with the given IDs after the video of
+// WithVideo, if any
+func WithPlaylist(ids ...string) Option {
+	return func(c *config) error {
+		for _, id := range ids {
+			if !yturl.ValidVideoID(id) {
+				return invalidOption("video ID %q in playlist", id)
+			}
+		}
+		c.playlist = append(c.playlist, ids...)
+		return nil
+	}
+}
+
+// WithList loads a list: the playlist with the ID list for ListTypePlaylist,
+// the results of the search query list for ListTypeSearch, or the uploads of
+// the channel list for ListTypeUserUploads
+func WithList(listType ListType, list string) Option {
+	return func(c *config) error {
+		if !listType.Valid() {
+			return invalidOption("list type %q", listType)
+		}
+		if listType == ListTypePlaylist && !yturl.ValidPlaylistID(list) {
+			return invalidOption("playlist ID %q", list)
+		}
+		c.listType, c.list = listType, list
+		return nil
+	}
+}
+
+// WithRef loads the video or playlist of a parsed link, with its start and
+// end times
+func WithRef(ref *yturl.Ref) Option {
+	return func(c *config) error {
+		if ref == nil {
+			return invalidOption("nil ref")
+		}
+		c.videoID = ref.VideoID
+		if ref.PlaylistID != "" {
+			c.list, c.listType = ref.PlaylistID, ref.ListType
+		}
+		c.start, c.end = ref.Start, ref.End
+		return nil
+	}
+}
+
+// WithSize sets the size of the player in pixels. Both dimensions must be
+// positive, or both zero to keep the default size.
+func WithSize(width, height int) Option {
+	return func(c *config) error {
+		if width < 0 || height < 0 || (width == 0) != (height == 0) {
+			return invalidOption("size %dx%d", width, height)
+		}
+		c.width, c.height = width, height
+		return nil
+	}
+}
+
+// WithStart starts the playback at d, rounded down to whole seconds
+func WithStart(d time.Duration) Option {
+	return func(c *config) error {
+		if d < 0 {
+			return invalidOption("negative start %v", d)
+		}
+		c.start = d
+		return nil
+	}
+}
+
+// WithEnd stops the playback at d, rounded up to whole seconds so the range
+// is never shortened, as PlayerParams.SetRange does
+func WithEnd(d time.Duration) Option {
+	return func(c *config) error {
+		if d <= 0 {
+			return invalidOption("end %v is not positive", d)
+		}
+		c.end = d
+		return nil
+	}
+}
+
+// WithLoop plays the video or playlist again and again
+func WithLoop() Option {
+	return func(c *config) error {
+		c.loop = true
+		return nil
+	}
+}
+
+// WithAutoplay starts the playback once the player is loaded. Browsers may
+// require WithMute for autoplay.
+func WithAutoplay() Option { return setVar("autoplay", 1) }
+
+// WithMute starts the player muted
+func WithMute() Option { return setVar("mute", 1) }
+
+// WithControls sets how the player controls are displayed
+func WithControls(mode ControlsMode) Option {
+	return func(c *config) error {
+		if mode < ControlsNotDisplay || mode > ControlsDisplayAfter {
+			return invalidOption("controls mode %d", int(mode))
+		}
+		c.vars["controls"] = int(mode)
+		return nil
+	}
+}
+
+// WithColor sets the color of the progress bar
+func WithColor(color ProgessBarColor) Option {
+	return func(c *config) error {
+		if color != Red && color != White {
+			return invalidOption("color %q", color)
+		}
+		c.vars["color"] = string(color)
+		return nil
+	}
+}
+
+// WithCaptions shows closed captions by default
+func WithCaptions() Option { return setVar("cc_load_policy", 1) }
+
+// WithLanguage sets the ISO 639-1 language of the player interface
+func WithLanguage(lang string) Option { return setVar("hl", lang) }
+
+// WithoutKeyboard ignores the keyboard controls
+func WithoutKeyboard() Option { return setVar("disablekb", 1) }
+
+// WithoutFullscreen removes the fullscreen button
+func WithoutFullscreen() Option { return setVar("fs", 0) }
+
+// WithoutAnnotations hides the video annotations
+func WithoutAnnotations() Option { return setVar("iv_load_policy", IvPolicyNotShown) }
+
+// WithRelatedFromSameChannel limits the related videos shown at the end to
+// the channel of the video
+func WithRelatedFromSameChannel() Option { return setVar("rel", 0) }
+
+// WithPlaysInline plays inline on iOS instead of fullscreen
+func WithPlaysInline() Option { return setVar("playsinline", 1) }
+
+// WithJSAPI enables the control of the player through the Iframe API
+func WithJSAPI() Option { return setVar("enablejsapi", 1) }
+
+// WithOrigin sets the scheme and host of the embedding page, such as
+// https://example.com, and enables the Iframe API
+func WithOrigin(origin string) Option {
+	return func(c *config) error {
+		normalized, ok := yturl.NormalizeOrigin(origin)
+		if !ok {
+			return invalidOption("origin %q must be a scheme and host", origin)
+		}
+		c.vars["origin"] = normalized
+		c.vars["enablejsapi"] = 1
+		return nil
+	}
+}
+
+// WithWidgetReferrer sets the URL where the player is embedded, for analytics
+func WithWidgetReferrer(referrer string) Option { return setVar("widget_referrer", referrer) }
+
+// WithQueuePolicy sets what the player does with the commands issued before
+// it is ready
+func WithQueuePolicy(policy QueuePolicy) Option {
+	return func(c *config) error {
+		c.queuePolicy = policy
+		return nil
+	}
+}
+
+// withHandler adds h to the callbacks of event. The handlers of an event are
+// called in the order their options were given.
+func withHandler(event EventType, h func(*Event)) Option {
+	return func(c *config) error {
+		c.handlers[event] = append(c.handlers[event], h)
+		return nil
+	}
+}
+
+// WithOnReady calls fn once the player is ready. Like the other WithOn*
+// options, it can be given more than once: every fn is called, in order.
+func WithOnReady(fn func(p *Player)) Option {
+	return withHandler(OnReady, func(e *Event) { fn(e.Target) })
+}
+
+// WithOnStateChange calls fn whenever the player's state changes
+func WithOnStateChange(fn func(p *Player, e StateChangeEvent)) Option {
+	return withHandler(OnStateChange, func(e *Event) { fn(e.Target, e.AsStateChange()) })
+}
+
+// WithOnQualityChange calls fn whenever the playback quality changes
+func WithOnQualityChange(fn func(p *Player, e QualityChangeEvent)) Option {
+	return withHandler(OnPlaybackQualityChange, func(e *Event) { fn(e.Target, e.AsQualityChange()) })
+}
+
+// WithOnRateChange calls fn whenever the playback rate changes
+func WithOnRateChange(fn func(p *Player, e RateChangeEvent)) Option {
+	return withHandler(OnPlaybackRateChange, func(e *Event) { fn(e.Target, e.AsRateChange()) })
+}
+
+// WithOnError calls fn when the player fires onError
+func WithOnError(fn func(p *Player, e ErrorEvent)) Option {
+	return withHandler(OnError, func(e *Event) { fn(e.Target, e.AsError()) })
+}
+
+// WithOnAPIChange calls fn when the player loads or unloads a module
+func WithOnAPIChange(fn func(p *Player, e APIChangeEvent)) Option {
+	return withHandler(OnApiChange, func(e *Event) { fn(e.Target, APIChangeEvent{}) })
+}
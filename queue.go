@@ -0,0 +1,160 @@
+package youtube
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrNotReady is the error of the commands rejected before onReady under
+// QueueFail, reported by LastError
+var ErrNotReady = errors.New("youtube: player not ready")
+
+// QueuePolicy is what a player created with NewPlayer does with the commands
+// issued before it fires onReady. Getters are never queued.
+type QueuePolicy int
+
+const (
+	// QueueAll keeps every command and runs them in order once the player is
+	// ready. It is the default.
+	QueueAll QueuePolicy = iota
+	// QueueCoalesce keeps only the last command of a kind: the last seek, the
+	// last volume, the last of play, pause and stop, and so on. A load or cue
+	// also drops the seeks and playback commands queued before it.
+	QueueCoalesce
+	// QueueDrop ignores the commands
+	QueueDrop
+	// QueueFail rejects the commands: they are not run, and LastError
+	// reports ErrNotReady
+	QueueFail
+)
+
+func (qp QueuePolicy) String() string {
+	switch qp {
+	case QueueAll:
+		return "all"
+	case QueueCoalesce:
+		return "coalesce"
+	case QueueDrop:
+		return "drop"
+	case QueueFail:
+		return "fail"
+	default:
+		return "QueuePolicy(" + strconv.Itoa(int(qp)) + ")"
+	}
+}
+
+// queuedCommand is a player method call waiting for onReady
+type queuedCommand struct {
+	kind string
+	name string
+	args []interface{}
+}
+
+// commandKinds group the commands QueueCoalesce keeps only the last of.
+// Commands missing from the map are never coalesced.
+var commandKinds = map[string]string{
+	"loadVideoById":      "load",
+	"cueVideoById":       "load",
+	"loadVideoByUrl":     "load",
+	"cuePlaylist":        "load",
+	"loadPlaylist":       "load",
+	"playVideo":          "playback",
+	"pauseVideo":         "playback",
+	"stopVideo":          "playback",
+	"seekTo":             "seek",
+	"mute":               "mute",
+	"unMute":             "mute",
+	"setVolume":          "volume",
+	"setPlaybackRate":    "rate",
+	"setPlaybackQuality": "quality",
+	"setLoop":            "loop",
+	"setShuffle":         "shuffle",
+}
+
+// SetQueuePolicy sets what the player does with the commands issued before it
+// fires onReady. Commands already queued are kept.
+func (p *Player) SetQueuePolicy(policy QueuePolicy) {
+	st := p.state()
+	st.mu.Lock()
+	st.queuePolicy = policy
+	st.mu.Unlock()
+}
+
+// LastError returns the error of the last command the player rejected, and
+// clears it. It returns nil if no command was rejected since the previous
+// call. Commands are only rejected before onReady under QueueFail, with
+// ErrNotReady. The methods of SafePlayer also return the rejections of their
+// own calls, without clearing LastError.
+func (p *Player) LastError() error {
+	return p.state().takeLastError()
+}
+
+// rejections returns the number of commands rejected so far
+func (st *playerState) rejections() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.rejected
+}
+
+func (st *playerState) takeLastError() error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	err := st.lastErr
+	st.lastErr = nil
+	return err
+}
+
+// command calls the player method name, or queues the call if the player is
+// not ready yet. Commands of a destroyed player are ignored.
+func (p *Player) command(name string, args ...interface{}) {
+	st := p.state()
+	st.mu.Lock()
+	if st.isDestroyed() {
+		st.mu.Unlock()
+		return
+	}
+	if !st.tracked || st.flushed {
+		st.mu.Unlock()
+		p.Call(name, args...)
+		return
+	}
+	defer st.mu.Unlock()
+	switch st.queuePolicy {
+	case QueueDrop:
+	case QueueFail:
+		st.lastErr = ErrNotReady
+		st.rejected++
+	case QueueCoalesce:
+		kind := commandKinds[name]
+		q := st.queue[:0]
+		for _, c := range st.queue {
+			if kind != "" && (c.kind == kind || kind == "load" && (c.kind == "seek" || c.kind == "playback")) {
+				continue
+			}
+			q = append(q, c)
+		}
+		st.queue = append(q, queuedCommand{kind: kind, name: name, args: args})
+	default:
+		st.queue = append(st.queue, queuedCommand{kind: commandKinds[name], name: name, args: args})
+	}
+}
+
+// flush runs the queued commands in order. Commands queued while flushing run
+// after them, and later commands are called right away.
+func (p *Player) flush() {
+	st := p.state()
+	for {
+		st.mu.Lock()
+		q := st.queue
+		st.queue = nil
+		if len(q) == 0 {
+			st.flushed = true
+			st.mu.Unlock()
+			return
+		}
+		st.mu.Unlock()
+		for _, c := range q {
+			p.Call(c.name, c.args...)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// RecoveryAction is what a RecoveryPolicy does about a player error
+type RecoveryAction int
+
+const (
+	// RecoveryNone leaves the player stopped
+	RecoveryNone RecoveryAction = iota
+	// RecoverySkip plays the next video of the playlist
+	RecoverySkip
+	// RecoveryRetry reloads the failed video where it failed
+	RecoveryRetry
+	// RecoveryFallback loads a replacement video
+	RecoveryFallback
+)
+
+func (a RecoveryAction) String() string {
+	switch a {
+	case RecoveryNone:
+		return "none"
+	case RecoverySkip:
+		return "skip"
+	case RecoveryRetry:
+		return "retry"
+	case RecoveryFallback:
+		return "fallback"
+	default:
+		return "RecoveryAction(" + strconv.Itoa(int(a)) + ")"
+	}
+}
+
+// Recovery is the decision of a RecoveryPolicy
+type Recovery struct {
+	Action RecoveryAction
+	// Delay is how long to wait before acting
+	Delay time.Duration
+	// VideoID is the video loaded by RecoveryFallback
+	VideoID string
+}
+
+// RecoveryPolicy decides how to recover from err. attempt counts the errors
+// of the same video in a row, starting at 1.
+type RecoveryPolicy func(c Controller, err *PlayerError, attempt int) Recovery
+
+// RecoveryReport describes an error and what was done about it
+type RecoveryReport struct {
+	Err     *PlayerError
+	Attempt int
+	Recovery
+}
+
+// SkipPolicy plays the next video when a playlist entry other than the last
+// one fails
+func SkipPolicy() RecoveryPolicy {
+	return func(c Controller, err *PlayerError, attempt int) Recovery {
+		if err.PlaylistIndex < 0 || err.PlaylistIndex+1 >= len(c.Playlist()) {
+			return Recovery{}
+		}
+		return Recovery{Action: RecoverySkip}
+	}
+}
+
+// RetryPolicy reloads the failed video up to max times, waiting backoff
+// before the first retry and doubling the wait before every next one
+func RetryPolicy(max int, backoff time.Duration) RecoveryPolicy {
+	return func(c Controller, err *PlayerError, attempt int) Recovery {
+		if attempt > max || (err.VideoID == "" && err.PlaylistIndex < 0) {
+			return Recovery{}
+		}
+		return Recovery{Action: RecoveryRetry, Delay: backoff << uint(attempt-1)}
+	}
+}
+
+// FallbackPolicy loads videoID in place of the failed video
+func FallbackPolicy(videoID string) RecoveryPolicy {
+	return func(c Controller, err *PlayerError, attempt int) Recovery {
+		if err.VideoID == videoID {
+			// the fallback itself failed
+			return Recovery{}
+		}
+		return Recovery{Action: RecoveryFallback, VideoID: videoID}
+	}
+}
+
+// ForErrors applies policy to the given errors only. Matching uses errors.Is,
+// so ErrNotForEmbedded also matches code 150.
+func ForErrors(policy RecoveryPolicy, errs ...Error) RecoveryPolicy {
+	return func(c Controller, err *PlayerError, attempt int) Recovery {
+		for _, e := range errs {
+			if errors.Is(err, e) {
+				return policy(c, err, attempt)
+			}
+		}
+		return Recovery{}
+	}
+}
+
+// FirstOf returns the decision of the first policy that does not answer
+// RecoveryNone
+func FirstOf(policies ...RecoveryPolicy) RecoveryPolicy {
+	return func(c Controller, err *PlayerError, attempt int) Recovery {
+		for _, policy := range policies {
+			if r := policy(c, err, attempt); r.Action != RecoveryNone {
+				return r
+			}
+		}
+		return Recovery{}
+	}
+}
+
+// DefaultRecoveryPolicy retries ErrNonHTML5 three times from one second of
+// backoff, skips playlist entries that are missing or not embeddable, and
+// otherwise loads fallback if it is not empty
+func DefaultRecoveryPolicy(fallback string) RecoveryPolicy {
+	policies := []RecoveryPolicy{
+		ForErrors(RetryPolicy(3, time.Second), ErrNonHTML5),
+		ForErrors(SkipPolicy(), ErrVideoNotFound, ErrNotForEmbedded),
+	}
+	if fallback != "" {
+		policies = append(policies, FallbackPolicy(fallback))
+	}
+	return FirstOf(policies...)
+}
+
+// Recover attaches a recovery policy to a player: it applies policy to every
+// onError event of c until ctx is done. The attempts start over once the
+// player plays or a video ends.
+// report, if not nil, is called after every decision is applied, including
+// RecoveryNone. A decision waiting for its Delay is dropped when the next
+// error occurs, as the decision about that error replaces it.
+// Recover does not block.
+func Recover(ctx context.Context, c Controller, policy RecoveryPolicy, report func(RecoveryReport)) {
+	events := c.Events(ctx, OnError, OnStateChange)
+	go func() {
+		var (
+			lastVideo string
+			lastIndex = -1
+			attempt   int
+
+			pending RecoveryReport // waiting for timer
+			timer   *time.Timer
+			due     <-chan time.Time
+		)
+		stop := func() {
+			if timer != nil {
+				timer.Stop()
+				timer, due = nil, nil
+			}
+		}
+		defer stop()
+		apply := func(rep RecoveryReport) {
+			applyRecovery(c, rep.Err, rep.Recovery)
+			if report != nil {
+				report(rep)
+			}
+		}
+		for {
+			select {
+			case <-due:
+				timer, due = nil, nil
+				apply(pending)
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				switch ev := ev.(type) {
+				case StateChangeEvent:
+					if ev.State == Playing || ev.State == Ended {
+						// the video recovered or played through: later
+						// errors start over
+						attempt = 0
+					}
+				case ErrorEvent:
+					stop()
+					err := ev.PlayerError()
+					if attempt > 0 && err.VideoID == lastVideo && err.PlaylistIndex == lastIndex {
+						attempt++
+					} else {
+						attempt = 1
+					}
+					lastVideo, lastIndex = err.VideoID, err.PlaylistIndex
+
+					rep := RecoveryReport{Err: err, Attempt: attempt, Recovery: policy(c, err, attempt)}
+					if rep.Delay <= 0 {
+						apply(rep)
+						continue
+					}
+					// the events keep flowing while the decision waits
+					pending = rep
+					timer = time.NewTimer(rep.Delay)
+					due = timer.C
+				}
+			}
+		}
+	}()
+}
+
+func applyRecovery(c Controller, err *PlayerError, r Recovery) {
+	switch r.Action {
+	case RecoverySkip:
+		c.NextVideo()
+	case RecoveryRetry:
+		if err.PlaylistIndex >= 0 {
+			c.PlayVideoAt(err.PlaylistIndex)
+			if err.Time > 0 {
+				c.SeekTo(err.Time.Seconds(), true)
+			}
+			return
+		}
+		c.LoadVideoByID(err.VideoID, err.Time.Seconds(), c.PlaybackQuality())
+	case RecoveryFallback:
+		c.LoadVideoByID(r.VideoID, 0, c.PlaybackQuality())
+	}
+}
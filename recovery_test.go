@@ -0,0 +1,87 @@
+package youtube_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/ytsim"
+)
+
+func TestRecoverRetriesAfterTheDelay(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan youtube.RecoveryReport, 10)
+	youtube.Recover(ctx, p, youtube.RetryPolicy(2, 50*time.Millisecond), func(r youtube.RecoveryReport) {
+		reports <- r
+	})
+	p.Advance(10 * time.Second)
+	p.InjectError(youtube.ErrNonHTML5)
+
+	// the event loop keeps running while the retry waits
+	events := p.Events(ctx, youtube.OnStateChange)
+	p.SetVolume(10)
+	select {
+	case r := <-reports:
+		t.Fatalf("report before the delay: %+v", r)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	select {
+	case r := <-reports:
+		if r.Action != youtube.RecoveryRetry || r.Attempt != 1 {
+			t.Errorf("report = %+v, want the first retry", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no retry")
+	}
+	if got := p.CurrentTime(); got != 10 {
+		t.Errorf("retried at %v, want 10", got)
+	}
+	if ev := <-events; ev != (youtube.StateChangeEvent{State: youtube.Unstarted}) {
+		t.Errorf("first state after the retry = %v, want unstarted", ev)
+	}
+}
+
+func TestRecoverStartsOverOncePlaying(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{BufferDelay: time.Second})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	p.Advance(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan youtube.RecoveryReport, 10)
+	youtube.Recover(ctx, p, youtube.RetryPolicy(1, 0), func(r youtube.RecoveryReport) {
+		reports <- r
+	})
+	fail := func() youtube.RecoveryReport {
+		t.Helper()
+		p.InjectError(youtube.ErrNonHTML5)
+		select {
+		case r := <-reports:
+			return r
+		case <-time.After(time.Second):
+			t.Fatal("no report")
+			return youtube.RecoveryReport{}
+		}
+	}
+
+	if r := fail(); r.Attempt != 1 || r.Action != youtube.RecoveryRetry {
+		t.Errorf("first error: %+v, want a retry", r)
+	}
+	// the retried video fails again while buffering
+	if r := fail(); r.Attempt != 2 || r.Action != youtube.RecoveryNone {
+		t.Errorf("second error: %+v, want to give up", r)
+	}
+	p.PlayVideo()
+	p.Advance(time.Second)
+	if r := fail(); r.Attempt != 1 || r.Action != youtube.RecoveryRetry {
+		t.Errorf("error after playing: %+v, want a first retry", r)
+	}
+}
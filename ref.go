@@ -0,0 +1,23 @@
+package youtube
+
+import "github.com/iocat/youtube/yturl"
+
+// NewLoadByIDOptionsFromRef prepares an argument for Player.LoadVideoByID2(arg)
+// and Player.CueVideoByID2(arg) playing the video of a parsed link
+func NewLoadByIDOptionsFromRef(ref *yturl.Ref) *LoadByIDOptions {
+	opts := NewLoadByIDOptions()
+	opts.VideoID = ref.VideoID
+	opts.SetRange(ref.Start, ref.End)
+	return opts
+}
+
+// NewCuePlaylistOptionsFromRef prepares an argument for Player.CuePlaylist2(arg)
+// and Player.LoadPlaylist2(arg) playing the playlist of a parsed link
+func NewCuePlaylistOptionsFromRef(ref *yturl.Ref) *CuePlaylistOptions {
+	opts := NewCuePlaylistOptions()
+	opts.ListType = ref.ListType
+	opts.List = ref.PlaylistID
+	opts.Index = ref.Index
+	opts.SetStart(ref.Start)
+	return opts
+}
@@ -0,0 +1,352 @@
+package youtube
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrAPIMissing is reported when the Iframe API script is not loaded, or
+	// the player lacks the called method
+	ErrAPIMissing = errors.New("youtube: Iframe API not loaded")
+	// ErrUndefined is reported when a getter returns undefined
+	ErrUndefined = errors.New("youtube: undefined result")
+)
+
+// CallError is returned by the methods of SafePlayer when the player call
+// fails
+type CallError struct {
+	// Method is the name of the Go method
+	Method string
+	// Kind is ErrNotReady, ErrDestroyed, ErrAPIMissing, ErrUndefined, or nil
+	// when the JS exception could not be classified
+	Kind error
+	// Cause is the JS exception, if any
+	Cause error
+}
+
+func (e *CallError) Error() string {
+	msg := "youtube: " + e.Method
+	if e.Kind != nil {
+		msg += ": " + strings.TrimPrefix(e.Kind.Error(), "youtube: ")
+	}
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the Kind, so errors.Is(err, ErrDestroyed) and the like work
+// on a CallError
+func (e *CallError) Unwrap() error {
+	return e.Kind
+}
+
+// SafePlayer exposes the methods of a Player returning errors instead of
+// panicking with the JS exceptions thrown by the player, e.g. when its iframe
+// was removed, it was destroyed, or the API is not loaded yet.
+type SafePlayer struct {
+	p *Player
+}
+
+// TryNewPlayer is NewPlayer returning an error instead of panicking, e.g.
+// with ErrAPIMissing when the Iframe API is not loaded yet
+func TryNewPlayer(iframeID string, props *Properties) (p *Player, err error) {
+	if !apiLoaded() {
+		return nil, &CallError{Method: "NewPlayer", Kind: ErrAPIMissing}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			cause, kind, ok := jsPanic(r)
+			if !ok {
+				panic(r)
+			}
+			p, err = nil, &CallError{Method: "NewPlayer", Kind: kind, Cause: cause}
+		}
+	}()
+	return NewPlayer(iframeID, props), nil
+}
+
+// Safe returns the error-returning view of the player
+func (p *Player) Safe() *SafePlayer {
+	return &SafePlayer{p: p}
+}
+
+// Player returns the wrapped player
+func (s *SafePlayer) Player() *Player {
+	return s.p
+}
+
+// do runs fn, a call to the player method jsName, and converts the JS
+// exception it panics with, or the rejection of the command under QueueFail,
+// into a *CallError. Other panics go through.
+func (s *SafePlayer) do(method, jsName string, fn func()) (err error) {
+	st := s.p.state()
+	select {
+	case <-st.destroyed:
+		return &CallError{Method: method, Kind: ErrDestroyed}
+	default:
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = s.classify(method, jsName, r)
+		}
+	}()
+	// the commands rejected during fn are its own: LastError is left for
+	// the caller to read
+	before := st.rejections()
+	fn()
+	if st.rejections() != before {
+		return &CallError{Method: method, Kind: ErrNotReady}
+	}
+	return nil
+}
+
+// value calls the player method jsName and checks that the result is defined
+func (s *SafePlayer) value(method, jsName string, args ...interface{}) (v JSValue, err error) {
+	err = s.do(method, jsName, func() { v = s.p.Call(jsName, args...) })
+	if err == nil && isNullish(v) {
+		err = &CallError{Method: method, Kind: ErrUndefined}
+	}
+	return v, err
+}
+
+func (s *SafePlayer) classify(method, jsName string, r interface{}) error {
+	cause, kind, ok := jsPanic(r)
+	if !ok {
+		panic(r)
+	}
+	if kind == nil {
+		kind = s.diagnose(jsName)
+	}
+	return &CallError{Method: method, Kind: kind, Cause: cause}
+}
+
+// diagnose guesses why the call to jsName threw
+func (s *SafePlayer) diagnose(jsName string) error {
+	st := s.p.state()
+	select {
+	case <-st.destroyed:
+		return ErrDestroyed
+	default:
+	}
+	if !apiLoaded() {
+		return ErrAPIMissing
+	}
+	ready := false
+	select {
+	case <-st.ready:
+		ready = true
+	default:
+	}
+	st.mu.Lock()
+	tracked := st.tracked
+	st.mu.Unlock()
+	// the API adds the methods to the player once it is ready
+	missing := !isFunction(s.p.Get(jsName))
+	switch {
+	case !ready && (tracked || missing):
+		return ErrNotReady
+	case missing:
+		return ErrAPIMissing
+	default:
+		return nil
+	}
+}
+
+// Queueing functions
+
+func (s *SafePlayer) LoadVideoByID(vid string, startSec float64, q Quality) error {
+	return s.do("LoadVideoByID", "loadVideoById", func() { s.p.LoadVideoByID(vid, startSec, q) })
+}
+
+func (s *SafePlayer) LoadVideoByID2(params *LoadByIDOptions) error {
+	return s.do("LoadVideoByID2", "loadVideoById", func() { s.p.LoadVideoByID2(params) })
+}
+
+func (s *SafePlayer) CueVideoByID(vid string, startSec float64, q Quality) error {
+	return s.do("CueVideoByID", "cueVideoById", func() { s.p.CueVideoByID(vid, startSec, q) })
+}
+
+func (s *SafePlayer) CueVideoByID2(params *LoadByIDOptions) error {
+	return s.do("CueVideoByID2", "cueVideoById", func() { s.p.CueVideoByID2(params) })
+}
+
+func (s *SafePlayer) LoadVideoByURL(url string, startSec float64, q Quality) error {
+	return s.do("LoadVideoByURL", "loadVideoByUrl", func() { s.p.LoadVideoByURL(url, startSec, q) })
+}
+
+func (s *SafePlayer) LoadVideoByURL2(params *LoadByURLOptions) error {
+	return s.do("LoadVideoByURL2", "loadVideoByUrl", func() { s.p.LoadVideoByURL2(params) })
+}
+
+func (s *SafePlayer) CuePlaylist(ids []string, index int, startSec float64, q Quality) error {
+	return s.do("CuePlaylist", "cuePlaylist", func() { s.p.CuePlaylist(ids, index, startSec, q) })
+}
+
+func (s *SafePlayer) CuePlaylist2(params *CuePlaylistOptions) error {
+	return s.do("CuePlaylist2", "cuePlaylist", func() { s.p.CuePlaylist2(params) })
+}
+
+func (s *SafePlayer) LoadPlaylist(ids []string, index int, startSec float64, q Quality) error {
+	return s.do("LoadPlaylist", "loadPlaylist", func() { s.p.LoadPlaylist(ids, index, startSec, q) })
+}
+
+func (s *SafePlayer) LoadPlaylist2(params *CuePlaylistOptions) error {
+	return s.do("LoadPlaylist2", "loadPlaylist", func() { s.p.LoadPlaylist2(params) })
+}
+
+// Playback controls and player settings
+
+func (s *SafePlayer) PlayVideo() error {
+	return s.do("PlayVideo", "playVideo", s.p.PlayVideo)
+}
+
+func (s *SafePlayer) PauseVideo() error {
+	return s.do("PauseVideo", "pauseVideo", s.p.PauseVideo)
+}
+
+func (s *SafePlayer) StopVideo() error {
+	return s.do("StopVideo", "stopVideo", s.p.StopVideo)
+}
+
+func (s *SafePlayer) SeekTo(seconds float64, allowSeekAhead bool) error {
+	return s.do("SeekTo", "seekTo", func() { s.p.SeekTo(seconds, allowSeekAhead) })
+}
+
+func (s *SafePlayer) NextVideo() error {
+	return s.do("NextVideo", "nextVideo", s.p.NextVideo)
+}
+
+func (s *SafePlayer) PreviousVideo() error {
+	return s.do("PreviousVideo", "previousVideo", s.p.PreviousVideo)
+}
+
+func (s *SafePlayer) PlayVideoAt(index int) error {
+	return s.do("PlayVideoAt", "playVideoAt", func() { s.p.PlayVideoAt(index) })
+}
+
+func (s *SafePlayer) Mute() error {
+	return s.do("Mute", "mute", s.p.Mute)
+}
+
+func (s *SafePlayer) UnMute() error {
+	return s.do("UnMute", "unMute", s.p.UnMute)
+}
+
+func (s *SafePlayer) IsMuted() (muted bool, err error) {
+	err = s.do("IsMuted", "isMuted", func() { muted = s.p.IsMuted() })
+	return
+}
+
+func (s *SafePlayer) SetVolume(vol int) error {
+	return s.do("SetVolume", "setVolume", func() { s.p.SetVolume(vol) })
+}
+
+func (s *SafePlayer) Volume() (vol int, err error) {
+	err = s.do("Volume", "getVolume", func() { vol = s.p.Volume() })
+	return
+}
+
+func (s *SafePlayer) SetSize(width int, height int) error {
+	return s.do("SetSize", "setSize", func() { s.p.SetSize(width, height) })
+}
+
+func (s *SafePlayer) PlaybackRate() (rate float64, err error) {
+	err = s.do("PlaybackRate", "getPlaybackRate", func() { rate = s.p.PlaybackRate() })
+	return
+}
+
+func (s *SafePlayer) SetPlaybackRate(suggestedRate float64) error {
+	return s.do("SetPlaybackRate", "setPlaybackRate", func() { s.p.SetPlaybackRate(suggestedRate) })
+}
+
+func (s *SafePlayer) AvailablePlaybackRates() (rates []float64, err error) {
+	err = s.do("AvailablePlaybackRates", "getAvailablePlaybackRates", func() { rates = s.p.AvailablePlaybackRates() })
+	return
+}
+
+func (s *SafePlayer) SetLoop(val bool) error {
+	return s.do("SetLoop", "setLoop", func() { s.p.SetLoop(val) })
+}
+
+func (s *SafePlayer) SetShuffle(val bool) error {
+	return s.do("SetShuffle", "setShuffle", func() { s.p.SetShuffle(val) })
+}
+
+// Playback status and video information
+
+func (s *SafePlayer) VideoLoadedFraction() (f float64, err error) {
+	err = s.do("VideoLoadedFraction", "getVideoLoadedFraction", func() { f = s.p.VideoLoadedFraction() })
+	return
+}
+
+func (s *SafePlayer) PlayerState() (state PlayerState, err error) {
+	err = s.do("PlayerState", "getPlayerState", func() { state = s.p.PlayerState() })
+	return
+}
+
+func (s *SafePlayer) CurrentTime() (t float64, err error) {
+	err = s.do("CurrentTime", "getCurrentTime", func() { t = s.p.CurrentTime() })
+	return
+}
+
+func (s *SafePlayer) PlaybackQuality() (q Quality, err error) {
+	err = s.do("PlaybackQuality", "getPlaybackQuality", func() { q = s.p.PlaybackQuality() })
+	return
+}
+
+func (s *SafePlayer) SetPlaybackQuality(suggested Quality) error {
+	return s.do("SetPlaybackQuality", "setPlaybackQuality", func() { s.p.SetPlaybackQuality(suggested) })
+}
+
+func (s *SafePlayer) AvailableQualityLevels() (q []Quality, err error) {
+	err = s.do("AvailableQualityLevels", "getAvailableQualityLevels", func() { q = s.p.AvailableQualityLevels() })
+	return
+}
+
+func (s *SafePlayer) Duration() (d float64, err error) {
+	err = s.do("Duration", "getDuration", func() { d = s.p.Duration() })
+	return
+}
+
+func (s *SafePlayer) VideoURL() (url string, err error) {
+	err = s.do("VideoURL", "getVideoUrl", func() { url = s.p.VideoURL() })
+	return
+}
+
+func (s *SafePlayer) VideoEmbedCode() (code string, err error) {
+	err = s.do("VideoEmbedCode", "getVideoEmbedCode", func() { code = s.p.VideoEmbedCode() })
+	return
+}
+
+// VideoData returns ErrUndefined if the player has no video data
+func (s *SafePlayer) VideoData() (*VideoData, error) {
+	v, err := s.value("VideoData", "getVideoData")
+	if err != nil {
+		return nil, err
+	}
+	return videoDataFrom(v), nil
+}
+
+// Playlist information
+
+func (s *SafePlayer) Playlist() (ids []string, err error) {
+	err = s.do("Playlist", "getPlaylist", func() { ids = s.p.Playlist() })
+	return
+}
+
+func (s *SafePlayer) PlaylistIndex() (i int, err error) {
+	err = s.do("PlaylistIndex", "getPlaylistIndex", func() { i = s.p.PlaylistIndex() })
+	return
+}
+
+// Iframe returns ErrUndefined if the player has no iframe
+func (s *SafePlayer) Iframe() (JSValue, error) {
+	return s.value("Iframe", "getIframe")
+}
+
+// Destroy returns ErrDestroyed if the player is already destroyed
+func (s *SafePlayer) Destroy() error {
+	return s.do("Destroy", "destroy", s.p.Destroy)
+}
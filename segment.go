@@ -0,0 +1,250 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// OnSegmentIteration is the event type of SegmentIterationEvent. The player
+// has no such event: it cannot be passed to AddEventListener.
+const OnSegmentIteration EventType = "onSegmentIteration"
+
+// ErrInvalidSegment is wrapped by the errors LoopSegment returns for an
+// invalid range or options
+var ErrInvalidSegment = errors.New("youtube: invalid segment")
+
+// DefaultSegmentInterval is the polling interval of LoopSegment
+const DefaultSegmentInterval = 100 * time.Millisecond
+
+// segmentEndMargin is how close to the end of the segment the playback
+// counts as having reached it
+const segmentEndMargin = 50 * time.Millisecond
+
+// SegmentIterationEvent is passed to the WithOnIteration callback of
+// LoopSegment when an iteration completes
+type SegmentIterationEvent struct {
+	// Iteration is the number of iterations completed, from 1
+	Iteration int
+	// Remaining is the number of iterations left, or -1 when looping forever
+	Remaining int
+}
+
+func (SegmentIterationEvent) EventType() EventType { return OnSegmentIteration }
+
+// SegmentOption configures LoopSegment
+type SegmentOption func(*segmentConfig)
+
+type segmentConfig struct {
+	leadIn      time.Duration
+	rates       []float64
+	ratesSet    bool
+	onIteration func(SegmentIterationEvent)
+	interval    time.Duration
+}
+
+// WithLeadIn pauses the playback for d at the start of the segment before
+// each iteration, e.g. to get ready to play along
+func WithLeadIn(d time.Duration) SegmentOption {
+	return func(c *segmentConfig) { c.leadIn = d }
+}
+
+// WithLoopRates sets the playback rate of each iteration: the first rate for
+// the first iteration and so on, the last one for the iterations left. For
+// instance WithLoopRates(0.5, 0.75, 1) practices slowly, then up to speed.
+// The previous rate is restored once the loop ends. The rates must be
+// positive, and there must be at least one.
+func WithLoopRates(rates ...float64) SegmentOption {
+	return func(c *segmentConfig) { c.rates, c.ratesSet = rates, true }
+}
+
+// WithOnIteration calls fn whenever an iteration completes. fn is called
+// from the goroutine polling the player.
+func WithOnIteration(fn func(SegmentIterationEvent)) SegmentOption {
+	return func(c *segmentConfig) { c.onIteration = fn }
+}
+
+// WithSegmentInterval sets the polling interval, DefaultSegmentInterval by
+// default or if d is not positive. The loop also wakes up when the playback
+// is due to reach the end of the segment.
+func WithSegmentInterval(d time.Duration) SegmentOption {
+	return func(c *segmentConfig) {
+		if d > 0 {
+			c.interval = d
+		}
+	}
+}
+
+// SegmentLoop is the handle of a loop started by LoopSegment
+type SegmentLoop struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+	finish func()
+}
+
+// Cancel stops looping, leaving the playback where it is. It is safe to call
+// Cancel more than once, and after the loop ended.
+func (l *SegmentLoop) Cancel() {
+	l.cancel()
+	l.end()
+}
+
+// Done is closed once the loop ended: all its iterations completed, it was
+// canceled or the player was destroyed
+func (l *SegmentLoop) Done() <-chan struct{} {
+	return l.done
+}
+
+func (l *SegmentLoop) end() {
+	l.once.Do(func() {
+		l.finish()
+		close(l.done)
+	})
+}
+
+// LoopSegment plays the range of the video from a to b count times, or forever
+// if count is not positive. See LoopSegment.
+func (p *Player) LoopSegment(a, b time.Duration, count int, opts ...SegmentOption) (*SegmentLoop, error) {
+	return LoopSegment(p, a, b, count, opts...)
+}
+
+// LoopSegment plays the range of the video from a to b count times, or forever
+// if count is not positive, seeking back to a whenever the playback reaches
+// b. The player is paused at b after the last iteration.
+//
+// Seeking past b completes the iteration. The loop ends when it is canceled,
+// its iterations complete or the player is destroyed.
+//
+// LoopSegment returns an error wrapping ErrInvalidSegment unless 0 <= a and b
+// is more than 50ms after a, the margin within which b counts as reached, or
+// if WithLoopRates is given no rate or a rate that is not positive.
+func LoopSegment(c Controller, a, b time.Duration, count int, opts ...SegmentOption) (*SegmentLoop, error) {
+	cfg := segmentConfig{interval: DefaultSegmentInterval}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if a < 0 || b-a <= segmentEndMargin {
+		return nil, fmt.Errorf("%w: range %v to %v", ErrInvalidSegment, a, b)
+	}
+	if cfg.ratesSet && len(cfg.rates) == 0 {
+		return nil, fmt.Errorf("%w: no loop rates", ErrInvalidSegment)
+	}
+	for _, rate := range cfg.rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("%w: loop rate %v is not positive", ErrInvalidSegment, rate)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &SegmentLoop{cancel: cancel, done: make(chan struct{})}
+
+	prevRate := c.PlaybackRate()
+	var (
+		iteration int
+		armed     bool
+		started   bool
+		wake      = make(chan struct{}, 1)
+
+		mu    sync.Mutex // guards timer and stop, used by Cancel
+		timer *time.Timer
+		stop  func()
+	)
+	l.finish = func() {
+		mu.Lock()
+		if stop != nil {
+			stop()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		mu.Unlock()
+		if len(cfg.rates) > 0 && c.Lifecycle() != LifecycleDestroyed {
+			c.SetPlaybackRate(prevRate)
+		}
+	}
+	// begin starts the iteration: the position reported until the seek to a
+	// is done is not taken for the end of the segment
+	begin := func() {
+		armed = false
+		if cfg.leadIn > 0 {
+			c.PauseVideo()
+			c.SeekTo(a.Seconds(), true)
+			select {
+			case <-time.After(cfg.leadIn):
+			case <-ctx.Done():
+				return
+			}
+		}
+		if len(cfg.rates) > 0 {
+			i := iteration
+			if i >= len(cfg.rates) {
+				i = len(cfg.rates) - 1
+			}
+			c.SetPlaybackRate(cfg.rates[i])
+		}
+		c.SeekTo(a.Seconds(), true)
+		c.PlayVideo()
+	}
+
+	mu.Lock()
+	stop = startPolling(c, cfg.interval, wake, func(s PlayerStatus) {
+		if ctx.Err() != nil {
+			return
+		}
+		if !started {
+			started = true
+			begin()
+			return
+		}
+		mu.Lock()
+		if timer != nil {
+			timer.Stop()
+		}
+		mu.Unlock()
+		if !armed {
+			if s.Position >= b-segmentEndMargin {
+				return
+			}
+			armed = true
+		}
+		if s.Position < b-segmentEndMargin && s.State != Ended {
+			if s.State == Playing && s.PlaybackRate > 0 {
+				d := time.Duration(float64(b-s.Position) / s.PlaybackRate)
+				mu.Lock()
+				timer = time.AfterFunc(d, func() {
+					select {
+					case wake <- struct{}{}:
+					default:
+					}
+				})
+				mu.Unlock()
+			}
+			return
+		}
+
+		iteration++
+		remaining := -1
+		if count > 0 {
+			remaining = count - iteration
+		}
+		if cfg.onIteration != nil {
+			cfg.onIteration(SegmentIterationEvent{Iteration: iteration, Remaining: remaining})
+		}
+		if remaining == 0 {
+			c.PauseVideo()
+			l.Cancel()
+			return
+		}
+		begin()
+	})
+	mu.Unlock()
+
+	remove := c.OnDestroy(l.end)
+	go func() {
+		<-l.done
+		remove()
+	}()
+	return l, nil
+}
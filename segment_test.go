@@ -0,0 +1,159 @@
+package youtube_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/ytsim"
+)
+
+func TestLoopSegmentRejectsInvalidArguments(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+
+	tests := []struct {
+		name string
+		a, b time.Duration
+		opts []youtube.SegmentOption
+	}{
+		{"negative start", -time.Second, time.Second, nil},
+		{"empty range", 5 * time.Second, 5 * time.Second, nil},
+		{"reversed range", 5 * time.Second, time.Second, nil},
+		{"within the end margin", 5 * time.Second, 5*time.Second + 50*time.Millisecond, nil},
+		{"no rates", 0, time.Second, []youtube.SegmentOption{youtube.WithLoopRates()}},
+		{"zero rate", 0, time.Second, []youtube.SegmentOption{youtube.WithLoopRates(0.5, 0)}},
+	}
+	for _, tt := range tests {
+		if l, err := youtube.LoopSegment(p, tt.a, tt.b, 1, tt.opts...); !errors.Is(err, youtube.ErrInvalidSegment) || l != nil {
+			t.Errorf("%s: LoopSegment = %v, %v, want ErrInvalidSegment", tt.name, l, err)
+		}
+	}
+
+	l, err := youtube.LoopSegment(p, 0, time.Second, 1, youtube.WithLoopRates(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Cancel()
+}
+
+const segmentInterval = 10 * time.Millisecond
+
+// playSegment plays p until the loop seeks back to a, then to b, once the loop
+// had a poll to see the playback inside the segment
+func playSegment(t *testing.T, p *ytsim.Player, a, b time.Duration) {
+	t.Helper()
+	eventually(t, "the seek back to the start of the segment", func() bool {
+		return p.CurrentTime() == a.Seconds() && p.PlayerState() == youtube.Playing
+	})
+	time.Sleep(3 * segmentInterval)
+	p.Advance(time.Duration(float64(b-a) / p.PlaybackRate()))
+}
+
+func TestLoopSegmentIterations(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	p.SetPlaybackRate(1.5)
+	a, b := 2*time.Second, 4*time.Second
+
+	iterations := make(chan youtube.SegmentIterationEvent, 10)
+	l, err := youtube.LoopSegment(p, a, b, 3,
+		youtube.WithLoopRates(0.5, 0.75),
+		youtube.WithOnIteration(func(ev youtube.SegmentIterationEvent) { iterations <- ev }),
+		youtube.WithSegmentInterval(segmentInterval))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Cancel()
+
+	// the last rate is kept for the iterations left
+	for i, rate := range []float64{0.5, 0.75, 0.75} {
+		playSegment(t, p, a, b)
+		if got := p.PlaybackRate(); got != rate {
+			t.Errorf("iteration %d: rate = %v, want %v", i+1, got, rate)
+		}
+		select {
+		case ev := <-iterations:
+			if want := (youtube.SegmentIterationEvent{Iteration: i + 1, Remaining: 2 - i}); ev != want {
+				t.Errorf("iteration %d: %+v, want %+v", i+1, ev, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("iteration %d: no event", i+1)
+		}
+	}
+
+	select {
+	case <-l.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the loop did not end after its iterations")
+	}
+	if p.PlayerState() != youtube.Paused || math.Abs(p.CurrentTime()-b.Seconds()) > 0.05 {
+		t.Errorf("after the loop: %v at %vs, want paused at the end of the segment", p.PlayerState(), p.CurrentTime())
+	}
+	if rate := p.PlaybackRate(); rate != 1.5 {
+		t.Errorf("rate after the loop = %v, want 1.5 restored", rate)
+	}
+	if len(iterations) > 0 {
+		t.Errorf("iteration after the last one: %+v", <-iterations)
+	}
+}
+
+func TestLoopSegmentLeadIn(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	a, b := 2*time.Second, 4*time.Second
+	leadIn := 200 * time.Millisecond
+
+	l, err := youtube.LoopSegment(p, a, b, 0,
+		youtube.WithLeadIn(leadIn),
+		youtube.WithSegmentInterval(segmentInterval))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Cancel()
+
+	for i := 0; i < 2; i++ {
+		eventually(t, "the pause at the start of the segment", func() bool {
+			return p.PlayerState() == youtube.Paused && p.CurrentTime() == a.Seconds()
+		})
+		paused := time.Now()
+		playSegment(t, p, a, b)
+		if d := time.Since(paused); d < leadIn-segmentInterval {
+			t.Errorf("iteration %d: played after %v, want a lead-in of %v", i+1, d, leadIn)
+		}
+	}
+}
+
+func TestLoopSegmentCancel(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	a, b := 2*time.Second, 4*time.Second
+
+	l, err := youtube.LoopSegment(p, a, b, 0,
+		youtube.WithLoopRates(0.5),
+		youtube.WithSegmentInterval(segmentInterval))
+	if err != nil {
+		t.Fatal(err)
+	}
+	playSegment(t, p, a, b)
+	eventually(t, "the seek back to the start of the segment", func() bool {
+		return p.CurrentTime() == a.Seconds()
+	})
+
+	l.Cancel()
+	<-l.Done()
+	if rate := p.PlaybackRate(); rate != 1 {
+		t.Errorf("rate after Cancel = %v, want 1 restored", rate)
+	}
+	p.Advance(3 * time.Second)
+	time.Sleep(5 * segmentInterval)
+	if p.CurrentTime() != 5 || p.PlayerState() != youtube.Playing {
+		t.Errorf("after Cancel: %v at %vs, want the playback to go on", p.PlayerState(), p.CurrentTime())
+	}
+}
@@ -0,0 +1,229 @@
+package youtube
+
+import (
+	"sync"
+	"time"
+)
+
+// stateKey is the property stamped on the JS player object to find its Go
+// side state. Event.Target and other wrappers of the same JS object share it.
+const stateKey = "__goYoutubeState"
+
+// destroyedStateID is stamped on destroyed players once their state is
+// dropped from the registry
+const destroyedStateID = -1
+
+// sweepInterval is how often SweepDetached runs while players are tracked
+const sweepInterval = 10 * time.Second
+
+var (
+	statesMu    sync.Mutex
+	states      = make(map[int]*playerState)
+	nextStateID = 1
+	sweepTimer  *time.Timer
+)
+
+// playerState holds everything the bindings keep on the Go side for one
+// JS player.
+type playerState struct {
+	mu          sync.Mutex
+	dispatchers map[EventType]*dispatcher
+	// callbacks runs the user callbacks of the player under WebAssembly
+	callbacks callbackQueue
+
+	// Hub tracks the lifecycle of the player: it is ready once onReady
+	// fires and destroyed once Destroy is called
+	*Hub
+
+	// releases free the JS functions created for the player's callbacks
+	releases []func()
+
+	// tracked is set for players created with NewPlayer, whose commands are
+	// queued until onReady
+	tracked     bool
+	flushed     bool // set once the queue has run
+	queuePolicy QueuePolicy
+	queue       []queuedCommand
+	lastErr     error // of the last rejected command
+	rejected    int   // counts the rejected commands, for SafePlayer
+
+	// player is the player the state was created for, checked by
+	// SweepDetached
+	player Player
+	// detached is set when a sweep found the iframe out of the document
+	detached bool
+}
+
+func (st *playerState) addRelease(release func()) {
+	st.mu.Lock()
+	st.releases = append(st.releases, release)
+	st.mu.Unlock()
+}
+
+func (st *playerState) releaseFuncs() {
+	st.mu.Lock()
+	releases := st.releases
+	st.releases = nil
+	st.mu.Unlock()
+	for _, release := range releases {
+		release()
+	}
+}
+
+// dropQueue forgets the commands queued before onReady
+func (st *playerState) dropQueue() {
+	st.mu.Lock()
+	st.queue = nil
+	st.mu.Unlock()
+}
+
+func (st *playerState) isDestroyed() bool {
+	select {
+	case <-st.destroyed:
+		return true
+	default:
+		return false
+	}
+}
+
+// track marks a player created with NewPlayer
+func (p *Player) track() {
+	st := p.state()
+	st.mu.Lock()
+	st.tracked = true
+	st.mu.Unlock()
+}
+
+// becomeReady marks the player ready and runs the commands queued before
+func (p *Player) becomeReady() {
+	p.state().MarkReady()
+	p.flush()
+}
+
+func (p *Player) state() *playerState {
+	statesMu.Lock()
+	if id := p.Get(stateKey); !isNullish(id) {
+		if id.Int() == destroyedStateID {
+			statesMu.Unlock()
+			return newDestroyedState()
+		}
+		if st, ok := states[id.Int()]; ok {
+			statesMu.Unlock()
+			return st
+		}
+	}
+	id := nextStateID
+	nextStateID++
+	st := &playerState{
+		dispatchers: make(map[EventType]*dispatcher),
+		Hub:         NewHub(),
+		player:      *p,
+	}
+	states[id] = st
+	p.Set(stateKey, id)
+	scheduleSweepLocked()
+	statesMu.Unlock()
+	return st
+}
+
+// scheduleSweepLocked runs SweepDetached after sweepInterval, and again
+// while players are tracked. statesMu must be held.
+func scheduleSweepLocked() {
+	if sweepTimer != nil {
+		return
+	}
+	sweepTimer = time.AfterFunc(sweepInterval, func() {
+		SweepDetached()
+		statesMu.Lock()
+		sweepTimer = nil
+		if len(states) > 0 {
+			scheduleSweepLocked()
+		}
+		statesMu.Unlock()
+	})
+}
+
+// SweepDetached releases the players whose iframe left the document without
+// Destroy, so that players dropped that way do not leak. A player is only
+// released once its iframe was found out of the document by two sweeps in a
+// row, as it may just be moving, e.g. when a framework renders the page
+// again. Players created with NewPlayer are only checked once ready.
+//
+// SweepDetached runs every 10 seconds while players are tracked. Calling it
+// releases the players sooner.
+func SweepDetached() {
+	statesMu.Lock()
+	snapshot := make(map[int]*playerState, len(states))
+	for id, st := range states {
+		snapshot[id] = st
+	}
+	statesMu.Unlock()
+
+	for id, st := range snapshot {
+		st.mu.Lock()
+		waiting := st.tracked && st.Lifecycle() == LifecycleCreating
+		st.mu.Unlock()
+		if waiting || st.Lifecycle() == LifecycleDestroyed {
+			continue
+		}
+		detached := st.player.detached()
+		st.mu.Lock()
+		drop := detached && st.detached
+		st.detached = detached
+		st.mu.Unlock()
+		if drop {
+			forgetState(id)
+			st.drop()
+		}
+	}
+}
+
+// detached reports whether the iframe of the player is known to have left
+// the document
+func (p *Player) detached() bool {
+	if !isFunction(p.Get("getIframe")) {
+		return false
+	}
+	iframe := p.Call("getIframe")
+	if isNullish(iframe) {
+		return false
+	}
+	connected := iframe.Get("isConnected")
+	return !isNullish(connected) && !connected.Bool()
+}
+
+// drop destroys the state of a player released by SweepDetached as Destroy
+// would, without calling the JS player
+func (st *playerState) drop() {
+	st.Destroy(func() {
+		st.dropQueue()
+		st.releaseListeners()
+		st.releaseFuncs()
+		st.player.Set(stateKey, destroyedStateID)
+	})
+}
+
+// newDestroyedState returns the state of a player whose state was dropped
+func newDestroyedState() *playerState {
+	st := &playerState{
+		dispatchers: make(map[EventType]*dispatcher),
+		Hub:         NewHub(),
+	}
+	st.MarkReady()
+	st.Destroy(nil)
+	return st
+}
+
+// forget drops the state of a destroyed player from the registry
+func (p *Player) forget() {
+	if id := p.Get(stateKey); !isNullish(id) {
+		forgetState(id.Int())
+	}
+	p.Set(stateKey, destroyedStateID)
+}
+
+func forgetState(id int) {
+	statesMu.Lock()
+	delete(states, id)
+	statesMu.Unlock()
+}
@@ -0,0 +1,268 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iocat/youtube/yttype"
+	"github.com/iocat/youtube/yturl"
+)
+
+// PlayerStatus is what the getters of the player report at one time, as
+// plain Go values
+type PlayerStatus struct {
+	State          PlayerState
+	Position       time.Duration
+	Length         time.Duration
+	LoadedFraction float64
+	Volume         int
+	Muted          bool
+	PlaybackRate   float64
+	PlaybackRates  []float64
+	Quality        Quality
+	Qualities      []Quality
+	VideoID        string
+	Title          string
+	Author         string
+	VideoQuality   Quality
+	VideoURL       string
+	EmbedCode      string
+	Playlist       []string
+	// PlaylistIndex is -1 when there is no playlist
+	PlaylistIndex int
+}
+
+// snapshotGetters are the getters read by Snapshot, by their key in the
+// snapshot JSON
+var snapshotGetters = []struct{ key, method string }{
+	{"state", "getPlayerState"},
+	{"position", "getCurrentTime"},
+	{"length", "getDuration"},
+	{"loaded", "getVideoLoadedFraction"},
+	{"volume", "getVolume"},
+	{"muted", "isMuted"},
+	{"rate", "getPlaybackRate"},
+	{"rates", "getAvailablePlaybackRates"},
+	{"quality", "getPlaybackQuality"},
+	{"qualities", "getAvailableQualityLevels"},
+	{"data", "getVideoData"},
+	{"url", "getVideoUrl"},
+	{"embed", "getVideoEmbedCode"},
+	{"playlist", "getPlaylist"},
+	{"index", "getPlaylistIndex"},
+}
+
+// snapshotJS returns the body of the JS function called with the player to
+// read every getter at once. Getters missing from a player that is not ready
+// yet, or throwing, are left out of the result.
+func snapshotJS() string {
+	var b strings.Builder
+	b.WriteString(`var r = {};
+function get(key, method) {
+	if (typeof p[method] !== "function") return;
+	try { r[key] = p[method](); } catch (e) {}
+}
+`)
+	for _, g := range snapshotGetters {
+		fmt.Fprintf(&b, "get(%q, %q);\n", g.key, g.method)
+	}
+	b.WriteString("return JSON.stringify(r);")
+	return b.String()
+}
+
+// snapshotJSON is the JSON the function of snapshotJS returns
+type snapshotJSON struct {
+	State     *int      `json:"state"`
+	Position  float64   `json:"position"`
+	Length    float64   `json:"length"`
+	Loaded    float64   `json:"loaded"`
+	Volume    int       `json:"volume"`
+	Muted     bool      `json:"muted"`
+	Rate      float64   `json:"rate"`
+	Rates     []float64 `json:"rates"`
+	Quality   string    `json:"quality"`
+	Qualities []string  `json:"qualities"`
+	Data      *struct {
+		VideoID      string `json:"video_id"`
+		Title        string `json:"title"`
+		Author       string `json:"author"`
+		VideoQuality string `json:"video_quality"`
+	} `json:"data"`
+	URL      string   `json:"url"`
+	Embed    string   `json:"embed"`
+	Playlist []string `json:"playlist"`
+	Index    *int     `json:"index"`
+}
+
+// Snapshot reads the status of the player in a single call into JS, rather
+// than one call per getter. Where the page's Content-Security-Policy forbids
+// eval, Snapshot calls the getters one by one instead. The status of a
+// destroyed player is unstarted.
+func (p *Player) Snapshot() PlayerStatus {
+	if p.Lifecycle() == LifecycleDestroyed {
+		return PlayerStatus{State: Unstarted, PlaylistIndex: -1}
+	}
+	var raw snapshotJSON
+	// a getter returning an unexpected type leaves its field zero, and the
+	// other fields are still decoded
+	_ = json.Unmarshal([]byte(callSnapshot(p)), &raw)
+	s := PlayerStatus{
+		State:          Unstarted,
+		Position:       yttype.Seconds(raw.Position),
+		Length:         yttype.Seconds(raw.Length),
+		LoadedFraction: raw.Loaded,
+		Volume:         raw.Volume,
+		Muted:          raw.Muted,
+		PlaybackRate:   raw.Rate,
+		PlaybackRates:  raw.Rates,
+		Quality:        lenientQuality(raw.Quality),
+		VideoURL:       raw.URL,
+		EmbedCode:      raw.Embed,
+		Playlist:       raw.Playlist,
+		PlaylistIndex:  -1,
+	}
+	for _, q := range raw.Qualities {
+		s.Qualities = append(s.Qualities, lenientQuality(q))
+	}
+	if raw.State != nil {
+		s.State = PlayerState(*raw.State)
+	}
+	if raw.Data != nil {
+		s.VideoID, s.Title, s.Author = raw.Data.VideoID, raw.Data.Title, raw.Data.Author
+		s.VideoQuality = lenientQuality(raw.Data.VideoQuality)
+	}
+	if raw.Index != nil && len(s.Playlist) > 0 {
+		s.PlaylistIndex = *raw.Index
+	}
+	return s
+}
+
+// lenientQuality parses a quality reported by the player, keeping names
+// unknown to ParseQuality as they are
+func lenientQuality(s string) Quality {
+	if q, err := yttype.ParseQuality(s); err == nil {
+		return q
+	}
+	return Quality(s)
+}
+
+// Snapshot returns the status of c: c.Snapshot() for a Player or any
+// controller with such a method, and otherwise the result of its getters.
+// The title, author and video quality are only known to the former.
+func Snapshot(c Controller) PlayerStatus {
+	if s, ok := c.(interface{ Snapshot() PlayerStatus }); ok {
+		return s.Snapshot()
+	}
+	s := PlayerStatus{
+		State:          c.PlayerState(),
+		Position:       yttype.Seconds(c.CurrentTime()),
+		Length:         yttype.Seconds(c.Duration()),
+		LoadedFraction: c.VideoLoadedFraction(),
+		Volume:         c.Volume(),
+		Muted:          c.IsMuted(),
+		PlaybackRate:   c.PlaybackRate(),
+		PlaybackRates:  c.AvailablePlaybackRates(),
+		Quality:        c.PlaybackQuality(),
+		Qualities:      c.AvailableQualityLevels(),
+		VideoURL:       c.VideoURL(),
+		EmbedCode:      c.VideoEmbedCode(),
+		Playlist:       c.Playlist(),
+		PlaylistIndex:  -1,
+	}
+	if ref, err := yturl.Parse(s.VideoURL); err == nil {
+		s.VideoID = ref.VideoID
+	}
+	if len(s.Playlist) > 0 {
+		s.PlaylistIndex = c.PlaylistIndex()
+	}
+	return s
+}
+
+// StatusField is a set of PlayerStatus fields, as reported by Diff
+type StatusField uint32
+
+const (
+	StatusState StatusField = 1 << iota
+	StatusPosition
+	StatusLength
+	StatusLoadedFraction
+	StatusVolume
+	StatusMuted
+	StatusPlaybackRate
+	StatusPlaybackRates
+	StatusQuality
+	StatusQualities
+	// StatusVideo is set when any of VideoID, Title, Author, VideoQuality,
+	// VideoURL and EmbedCode changed
+	StatusVideo
+	StatusPlaylist
+	StatusPlaylistIndex
+)
+
+var statusFieldNames = []string{
+	"State", "Position", "Length", "LoadedFraction", "Volume", "Muted",
+	"PlaybackRate", "PlaybackRates", "Quality", "Qualities", "Video",
+	"Playlist", "PlaylistIndex",
+}
+
+// Has reports whether every field of g is in f
+func (f StatusField) Has(g StatusField) bool {
+	return f&g == g
+}
+
+func (f StatusField) String() string {
+	if f == 0 {
+		return "none"
+	}
+	var names []string
+	for i, name := range statusFieldNames {
+		if f.Has(1 << uint(i)) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Diff returns the fields of s that differ from prev
+func (s PlayerStatus) Diff(prev PlayerStatus) StatusField {
+	var f StatusField
+	set := func(field StatusField, changed bool) {
+		if changed {
+			f |= field
+		}
+	}
+	set(StatusState, s.State != prev.State)
+	set(StatusPosition, s.Position != prev.Position)
+	set(StatusLength, s.Length != prev.Length)
+	set(StatusLoadedFraction, s.LoadedFraction != prev.LoadedFraction)
+	set(StatusVolume, s.Volume != prev.Volume)
+	set(StatusMuted, s.Muted != prev.Muted)
+	set(StatusPlaybackRate, s.PlaybackRate != prev.PlaybackRate)
+	set(StatusPlaybackRates, !equalSlices(len(s.PlaybackRates), len(prev.PlaybackRates),
+		func(i int) bool { return s.PlaybackRates[i] == prev.PlaybackRates[i] }))
+	set(StatusQuality, s.Quality != prev.Quality)
+	set(StatusQualities, !equalSlices(len(s.Qualities), len(prev.Qualities),
+		func(i int) bool { return s.Qualities[i] == prev.Qualities[i] }))
+	set(StatusVideo, s.VideoID != prev.VideoID || s.Title != prev.Title || s.Author != prev.Author ||
+		s.VideoQuality != prev.VideoQuality || s.VideoURL != prev.VideoURL || s.EmbedCode != prev.EmbedCode)
+	set(StatusPlaylist, !equalSlices(len(s.Playlist), len(prev.Playlist),
+		func(i int) bool { return s.Playlist[i] == prev.Playlist[i] }))
+	set(StatusPlaylistIndex, s.PlaylistIndex != prev.PlaylistIndex)
+	return f
+}
+
+// equalSlices reports whether slices of lengths n and m have the same
+// elements, compared by same
+func equalSlices(n, m int, same func(i int) bool) bool {
+	if n != m {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if !same(i) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+package youtube
+
+import (
+	"reflect"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+// fakeStatusYT is a YT.Player with getters reporting unusual values
+const fakeStatusYT = `globalThis.YT = {Player: function(id, cfg) {
+	var self = this;
+	self.cfg = cfg;
+	self.getPlayerState = function() { return 1; };
+	self.getCurrentTime = function() { return 12.5; };
+	self.getVolume = function() { throw new Error("boom"); };
+	self.getPlaybackQuality = function() { return "HD720"; };
+	self.getAvailableQualityLevels = function() { return ["hd720", "hd4320"]; };
+	self.getPlaylistIndex = function() { return "x"; };
+	self.getVideoData = function() { return {video_id: "dQw4w9WgXcQ", title: "T", video_quality: "Large"}; };
+	self.getIframe = function() { return {isConnected: true}; };
+	self.addEventListener = function() {};
+	self.removeEventListener = function() {};
+	globalThis.ytLast = self;
+}};`
+
+func TestSnapshotWithAndWithoutEval(t *testing.T) {
+	js.Global().Call("eval", fakeStatusYT)
+	p := NewPlayer("player", NewProperties())
+	fake := js.Global().Get("ytLast")
+	fake.Get("cfg").Get("events").Call("onReady", map[string]interface{}{"target": fake})
+
+	want := PlayerStatus{
+		State:         Playing,
+		Position:      12500 * time.Millisecond,
+		Quality:       HD720,
+		Qualities:     []Quality{HD720, "hd4320"},
+		VideoID:       "dQw4w9WgXcQ",
+		Title:         "T",
+		VideoQuality:  Large,
+		PlaylistIndex: -1,
+	}
+	if got := p.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// a Content-Security-Policy without unsafe-eval
+	snapshotFunc, snapshotNoEval = js.Undefined(), false
+	function := js.Global().Get("Function")
+	js.Global().Call("eval", `globalThis.Function = function() { throw new EvalError("unsafe-eval"); };`)
+	defer js.Global().Set("Function", function)
+	defer func() { snapshotFunc, snapshotNoEval = js.Undefined(), false }()
+	if got := p.Snapshot(); !snapshotNoEval || !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() without eval =\n%+v\nwant\n%+v", got, want)
+	}
+}
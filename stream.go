@@ -0,0 +1,42 @@
+package youtube
+
+import "context"
+
+// EventStreamBuffer is the capacity of the channels returned by Player.Events
+const EventStreamBuffer = 32
+
+var allEventTypes = []EventType{
+	OnReady,
+	OnStateChange,
+	OnPlaybackQualityChange,
+	OnPlaybackRateChange,
+	OnError,
+	OnApiChange,
+}
+
+// Events returns a channel delivering the typed events of the given types,
+// or of every type if none is given. The channel is buffered with
+// EventStreamBuffer slots; when the receiver falls behind, the oldest pending
+// event is dropped to make room so the JS callbacks never block.
+//
+// The listeners are removed and the channel is closed once ctx is done or
+// the player is destroyed.
+func (p *Player) Events(ctx context.Context, types ...EventType) <-chan TypedEvent {
+	if len(types) == 0 {
+		types = allEventTypes
+	}
+	return newStream(ctx, p.state().destroyed, func(send func(TypedEvent)) func() {
+		ls := make([]Listener, 0, len(types))
+		for _, t := range types {
+			t := t
+			ls = append(ls, p.addListener(t, func(e *Event) {
+				send(e.Typed(t))
+			}, true))
+		}
+		return func() {
+			for _, l := range ls {
+				l.Remove()
+			}
+		}
+	})
+}
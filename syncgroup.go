@@ -0,0 +1,410 @@
+package youtube
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// The defaults of the fields of SyncGroup
+const (
+	DefaultSyncInterval   = 250 * time.Millisecond
+	DefaultNudgeThreshold = 40 * time.Millisecond
+	DefaultSeekThreshold  = time.Second
+	DefaultNudgeRate      = 0.05
+)
+
+// jumpTolerance is how far the leader may move from where its playback would
+// have brought it before the move is taken for a seek
+const jumpTolerance = 250 * time.Millisecond
+
+// SyncGroup keeps players in lockstep, e.g. the angles of a recording or a
+// video and its dub: the followers mirror the play, pause, seeks and rate of
+// the leader.
+//
+//	g := &youtube.SyncGroup{Leader: main, Followers: []youtube.Controller{dub}}
+//	stop := g.Start()
+//
+// The drift of each follower from the leader, measured with CurrentTime, is
+// corrected by nudging its playback rate, or by seeking it to the leader past
+// SeekThreshold. A seek of the leader is mirrored by seeking the followers
+// right away, within an Interval.
+//
+// When any player of the group buffers while the group is playing, the
+// others are paused until it is done.
+//
+// The group only syncs the times of the videos loaded: a change of video of
+// the leader, e.g. to the next of its playlist, is not mirrored. Load the
+// matching videos on the followers; the group then brings them to the time
+// of the leader.
+type SyncGroup struct {
+	Leader    Controller
+	Followers []Controller
+	// Interval is how often the drift is measured, DefaultSyncInterval if
+	// zero
+	Interval time.Duration
+	// NudgeThreshold is the drift above which the rate of a follower is
+	// nudged, DefaultNudgeThreshold if zero. A nudged follower gets the rate
+	// of the leader back once its drift is below half of it, so it does not
+	// flip between the two rates around the threshold.
+	NudgeThreshold time.Duration
+	// SeekThreshold is the drift above which a follower is seeked to the
+	// leader, DefaultSeekThreshold if zero
+	SeekThreshold time.Duration
+	// NudgeRate is the fraction of the rate of the leader added to or removed
+	// from the rate of a drifting follower, DefaultNudgeRate if zero. A
+	// follower reporting its available rates is nudged to the closest one
+	// instead, as the player would round the rate back, but only within
+	// twice NudgeRate of the rate of the leader: a follower without such a
+	// rate is seeked, as a coarser rate would overshoot.
+	NudgeRate float64
+}
+
+// syncRun is the state of a started SyncGroup. It is only used by the
+// goroutine of the group.
+type syncRun struct {
+	g       *SyncGroup
+	members []Controller // the leader first
+	alive   []bool
+	states  []PlayerState
+	stalled []bool // buffering since playing, rather than starting to play
+	nudged  []bool
+
+	rate    float64 // of the leader
+	playing bool    // the leader plays, or is held to play
+	holding bool
+
+	// the last measure of the leader, to tell its seeks
+	leaderPos     time.Duration
+	leaderAt      time.Time
+	leaderPlaying bool
+}
+
+// syncEvent is an event of the member of index i. closed is set once the
+// events of the member end, as it is destroyed.
+type syncEvent struct {
+	i      int
+	ev     TypedEvent
+	closed bool
+}
+
+// Start starts keeping the group in sync, until stop is called or the leader
+// is destroyed. Followers that are destroyed leave the group.
+func (g *SyncGroup) Start() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &syncRun{g: g, members: append([]Controller{g.Leader}, g.Followers...)}
+	n := len(r.members)
+	r.alive, r.states = make([]bool, n), make([]PlayerState, n)
+	r.stalled, r.nudged = make([]bool, n), make([]bool, n)
+
+	events := make(chan syncEvent, EventStreamBuffer)
+	for i, m := range r.members {
+		r.alive[i] = true
+		ch := m.Events(ctx, OnStateChange, OnPlaybackRateChange)
+		go func(i int, ch <-chan TypedEvent) {
+			for ev := range ch {
+				select {
+				case events <- syncEvent{i: i, ev: ev}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case events <- syncEvent{i: i, closed: true}:
+			case <-ctx.Done():
+			}
+		}(i, ch)
+	}
+
+	go func() {
+		defer r.restoreRates()
+		ticker := time.NewTicker(r.interval())
+		defer ticker.Stop()
+
+		r.align()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e := <-events:
+				if e.closed {
+					if e.i == 0 {
+						// the leader is destroyed
+						return
+					}
+					r.alive[e.i] = false
+					r.release()
+					continue
+				}
+				switch ev := e.ev.(type) {
+				case StateChangeEvent:
+					// the event may be stale, e.g. the pause of a hold
+					// released since: the current state is what counts
+					r.stateChanged(e.i, r.members[e.i].PlayerState())
+					if e.i == 0 {
+						// the real player buffers after a seek
+						r.followJump(Snapshot(r.members[0]))
+					}
+				case RateChangeEvent:
+					if e.i == 0 {
+						r.rateChanged(ev.Rate)
+					}
+				}
+			case <-ticker.C:
+				r.correct()
+			}
+		}
+	}()
+	return cancel
+}
+
+func (r *syncRun) interval() time.Duration {
+	if r.g.Interval > 0 {
+		return r.g.Interval
+	}
+	return DefaultSyncInterval
+}
+
+func (r *syncRun) nudgeThreshold() time.Duration {
+	if r.g.NudgeThreshold > 0 {
+		return r.g.NudgeThreshold
+	}
+	return DefaultNudgeThreshold
+}
+
+func (r *syncRun) seekThreshold() time.Duration {
+	if r.g.SeekThreshold > 0 {
+		return r.g.SeekThreshold
+	}
+	return DefaultSeekThreshold
+}
+
+func (r *syncRun) nudgeRate() float64 {
+	if r.g.NudgeRate > 0 {
+		return r.g.NudgeRate
+	}
+	return DefaultNudgeRate
+}
+
+// followers calls fn with the index of each follower still in the group
+func (r *syncRun) followers(fn func(i int, c Controller)) {
+	for i := 1; i < len(r.members); i++ {
+		if r.alive[i] {
+			fn(i, r.members[i])
+		}
+	}
+}
+
+// align brings the followers to the position, rate and state of the leader
+func (r *syncRun) align() {
+	s := Snapshot(r.members[0])
+	r.states[0] = s.State
+	r.rate = s.PlaybackRate
+	r.playing = polling(s.State)
+	r.leaderPos, r.leaderAt, r.leaderPlaying = s.Position, time.Now(), s.State == Playing
+	r.followers(func(i int, c Controller) {
+		c.SetPlaybackRate(r.rate)
+		if drift := c.CurrentTime() - s.Position.Seconds(); math.Abs(drift) > r.nudgeThreshold().Seconds() {
+			// seeking a follower in place would only make it buffer
+			c.SeekTo(s.Position.Seconds(), true)
+		}
+		if r.playing {
+			c.PlayVideo()
+		} else {
+			c.PauseVideo()
+		}
+		r.states[i] = c.PlayerState()
+	})
+}
+
+func (r *syncRun) stateChanged(i int, state PlayerState) {
+	// resuming goes through buffering too: only a member buffering while it
+	// played holds the group
+	r.stalled[i] = state == Buffering && (r.states[i] == Playing || r.stalled[i])
+	r.states[i] = state
+	if r.hold() || r.release() {
+		return
+	}
+	if i != 0 || r.holding {
+		// the followers only mirror the leader, and the pauses of a hold are
+		// the group's own
+		return
+	}
+	switch state {
+	case Playing:
+		r.playing = true
+		r.followers(func(i int, c Controller) {
+			if !polling(r.states[i]) {
+				c.PlayVideo()
+			}
+		})
+	case Buffering:
+	default:
+		r.playing = false
+		pos := r.members[0].CurrentTime()
+		r.followers(func(_ int, c Controller) {
+			c.PauseVideo()
+			c.SeekTo(pos, true)
+		})
+	}
+}
+
+// buffering reports whether a member of the group stalled
+func (r *syncRun) buffering() bool {
+	for i, stalled := range r.stalled {
+		if r.alive[i] && stalled {
+			return true
+		}
+	}
+	return false
+}
+
+// hold pauses the group when a member stalls while it plays. It reports
+// whether it did.
+func (r *syncRun) hold() bool {
+	if r.holding || !r.playing || !r.buffering() {
+		return false
+	}
+	r.holding = true
+	for i, c := range r.members {
+		if r.alive[i] && !r.stalled[i] {
+			c.PauseVideo()
+		}
+	}
+	return true
+}
+
+// release resumes the group held once no member buffers anymore. It reports
+// whether it did.
+func (r *syncRun) release() bool {
+	if !r.holding || r.buffering() {
+		return false
+	}
+	r.holding = false
+	if !r.playing {
+		return true
+	}
+	// the drift the hold left is corrected as usual, as seeking here could
+	// make a member buffer again
+	for i, c := range r.members {
+		if r.alive[i] && !polling(r.states[i]) {
+			c.PlayVideo()
+		}
+	}
+	return true
+}
+
+func (r *syncRun) rateChanged(rate float64) {
+	r.rate = rate
+	r.followers(func(i int, c Controller) {
+		c.SetPlaybackRate(rate)
+		r.nudged[i] = false
+	})
+}
+
+// correct measures the drift of the followers and corrects it
+func (r *syncRun) correct() {
+	leader := Snapshot(r.members[0])
+	r.followJump(leader)
+	if r.holding {
+		return
+	}
+	leaderPlaying := leader.State == Playing
+	r.followers(func(i int, c Controller) {
+		s := Snapshot(c)
+		if s.State == Ended {
+			// a shorter follower stays at its end
+			return
+		}
+		if leaderPlaying && !polling(s.State) {
+			// e.g. the follower was paused from its own controls
+			c.PlayVideo()
+		}
+		drift := s.Position - leader.Position
+		abs := drift
+		if abs < 0 {
+			abs = -abs
+		}
+		// a nudged follower is corrected until it is well within the
+		// threshold
+		nudge := abs > r.nudgeThreshold() || (r.nudged[i] && abs > r.nudgeThreshold()/2)
+		switch {
+		case abs > r.seekThreshold() || (!polling(leader.State) && abs > r.nudgeThreshold()):
+			// the rate cannot correct the drift of a paused group
+			c.SeekTo(leader.Position.Seconds(), true)
+			r.unnudge(i, c)
+		case leaderPlaying && nudge:
+			// an ahead follower is slowed down, a late one sped up
+			rate, ok := r.nudgedRate(s.PlaybackRates, drift > 0)
+			if !ok {
+				c.SeekTo(leader.Position.Seconds(), true)
+				r.unnudge(i, c)
+				break
+			}
+			c.SetPlaybackRate(rate)
+			r.nudged[i] = true
+		default:
+			r.unnudge(i, c)
+		}
+	})
+}
+
+// followJump seeks the followers to the leader if it jumped since it was last
+// measured, rather than leaving the drift to the nudges
+func (r *syncRun) followJump(leader PlayerStatus) {
+	now := time.Now()
+	expected := r.leaderPos
+	if r.leaderPlaying {
+		expected += time.Duration(float64(now.Sub(r.leaderAt)) * r.rate)
+	}
+	r.leaderPos, r.leaderAt, r.leaderPlaying = leader.Position, now, leader.State == Playing
+	if diff := leader.Position - expected; diff <= jumpTolerance && diff >= -jumpTolerance {
+		return
+	}
+	r.followers(func(i int, c Controller) {
+		if math.Abs(c.CurrentTime()-leader.Position.Seconds()) > r.nudgeThreshold().Seconds() {
+			c.SeekTo(leader.Position.Seconds(), true)
+			r.unnudge(i, c)
+		}
+	})
+}
+
+// nudgedRate returns the rate to correct a drift with: the rate of the
+// leader changed by NudgeRate if the follower supports any rate, or else the
+// closest rate of the follower on the way. It reports false when that rate is
+// further than twice NudgeRate from the rate of the leader.
+func (r *syncRun) nudgedRate(rates []float64, slower bool) (float64, bool) {
+	target := r.rate * (1 + r.nudgeRate())
+	if slower {
+		target = r.rate * (1 - r.nudgeRate())
+	}
+	if len(rates) == 0 {
+		return target, true
+	}
+	best, ok := 0.0, false
+	for _, rate := range rates {
+		if rate == target {
+			return target, true
+		}
+		if (slower && rate < r.rate && (!ok || rate > best)) || (!slower && rate > r.rate && (!ok || rate < best)) {
+			best, ok = rate, true
+		}
+	}
+	return best, ok && math.Abs(best-r.rate) <= 2*r.nudgeRate()*r.rate
+}
+
+func (r *syncRun) unnudge(i int, c Controller) {
+	if r.nudged[i] {
+		c.SetPlaybackRate(r.rate)
+		r.nudged[i] = false
+	}
+}
+
+// restoreRates sets the rate of the leader back on the nudged followers
+func (r *syncRun) restoreRates() {
+	r.followers(func(i int, c Controller) {
+		if c.Lifecycle() != LifecycleDestroyed {
+			r.unnudge(i, c)
+		}
+	})
+}
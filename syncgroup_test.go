@@ -0,0 +1,160 @@
+package youtube_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/ytsim"
+)
+
+const syncInterval = 10 * time.Millisecond
+
+// startSync starts a group of two players playing the same video, the
+// follower simulated with cfg
+func startSync(t *testing.T, cfg ytsim.Config) (leader, follower *ytsim.Player, stop func()) {
+	t.Helper()
+	leader = ytsim.NewPlayer(ytsim.Config{})
+	follower = ytsim.NewPlayer(cfg)
+	for _, p := range []*ytsim.Player{leader, follower} {
+		p.Ready()
+		p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	}
+	follower.Advance(cfg.BufferDelay)
+	g := &youtube.SyncGroup{Leader: leader, Followers: []youtube.Controller{follower}, Interval: syncInterval}
+	stop = g.Start()
+	// let the group align the players before they drift
+	time.Sleep(2 * syncInterval)
+	return leader, follower, stop
+}
+
+// eventually waits for cond, failing the test after a second
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(syncInterval)
+	}
+}
+
+func TestSyncGroupSeeksWithoutACloseRate(t *testing.T) {
+	// the default rates go by steps of 0.25, far more than a nudge
+	leader, follower, stop := startSync(t, ytsim.Config{})
+	defer stop()
+
+	follower.Advance(200 * time.Millisecond)
+	eventually(t, "the follower to be seeked back", func() bool {
+		return follower.CurrentTime() == leader.CurrentTime()
+	})
+	if rate := follower.PlaybackRate(); rate != 1 {
+		t.Errorf("follower rate = %v, want 1", rate)
+	}
+}
+
+func TestSyncGroupNudgesWithHysteresis(t *testing.T) {
+	leader, follower, stop := startSync(t, ytsim.Config{Rates: []float64{0.95, 1, 1.05}})
+	defer stop()
+
+	follower.Advance(100 * time.Millisecond)
+	eventually(t, "the follower to be slowed down", func() bool {
+		return follower.PlaybackRate() == 0.95
+	})
+
+	// within the threshold but above half of it: still nudged
+	leader.Advance(70 * time.Millisecond)
+	time.Sleep(5 * syncInterval)
+	if rate := follower.PlaybackRate(); rate != 0.95 {
+		t.Errorf("follower rate at 30ms of drift = %v, want 0.95", rate)
+	}
+
+	leader.Advance(20 * time.Millisecond)
+	eventually(t, "the follower to get the rate of the leader back", func() bool {
+		return follower.PlaybackRate() == 1
+	})
+}
+
+func TestSyncGroupFollowsLeaderSeeks(t *testing.T) {
+	// the drift of the seek is below SeekThreshold and could be nudged
+	leader, follower, stop := startSync(t, ytsim.Config{Rates: []float64{0.95, 1, 1.05}})
+	defer stop()
+
+	leader.SeekTo(0.5, true)
+	eventually(t, "the follower to be seeked to the leader", func() bool {
+		return follower.CurrentTime() == 0.5
+	})
+	if rate := follower.PlaybackRate(); rate != 1 {
+		t.Errorf("follower rate = %v, want 1", rate)
+	}
+}
+
+func TestSyncGroupMirrorsPlayAndPause(t *testing.T) {
+	leader, follower, stop := startSync(t, ytsim.Config{})
+	defer stop()
+
+	leader.Advance(time.Second)
+	leader.PauseVideo()
+	eventually(t, "the follower to pause with the leader", func() bool {
+		return follower.PlayerState() == youtube.Paused && follower.CurrentTime() == 1
+	})
+	leader.PlayVideo()
+	eventually(t, "the follower to play with the leader", func() bool {
+		return follower.PlayerState() == youtube.Playing
+	})
+}
+
+func TestSyncGroupHoldsWhileBuffering(t *testing.T) {
+	const delay = time.Second
+	leader, follower, stop := startSync(t, ytsim.Config{BufferDelay: delay})
+	defer stop()
+
+	// a seek in place makes the follower buffer while playing
+	follower.SeekTo(follower.CurrentTime(), true)
+	eventually(t, "the leader to be held", func() bool {
+		return leader.PlayerState() == youtube.Paused
+	})
+	follower.Advance(delay)
+	eventually(t, "the leader to be released", func() bool {
+		return leader.PlayerState() == youtube.Playing
+	})
+	if state := follower.PlayerState(); state != youtube.Playing {
+		t.Errorf("follower state = %v, want playing", state)
+	}
+}
+
+func TestSyncGroupDropsDestroyedFollowers(t *testing.T) {
+	const delay = time.Second
+	leader := ytsim.NewPlayer(ytsim.Config{})
+	stalled := ytsim.NewPlayer(ytsim.Config{BufferDelay: delay})
+	follower := ytsim.NewPlayer(ytsim.Config{})
+	for _, p := range []*ytsim.Player{leader, stalled, follower} {
+		p.Ready()
+		p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	}
+	stalled.Advance(delay)
+	g := &youtube.SyncGroup{
+		Leader:    leader,
+		Followers: []youtube.Controller{stalled, follower},
+		Interval:  syncInterval,
+	}
+	stop := g.Start()
+	defer stop()
+	time.Sleep(2 * syncInterval)
+
+	stalled.SeekTo(stalled.CurrentTime(), true)
+	eventually(t, "the group to be held", func() bool {
+		return leader.PlayerState() == youtube.Paused && follower.PlayerState() == youtube.Paused
+	})
+	// the destroyed follower no longer holds the group
+	stalled.Destroy()
+	eventually(t, "the group to be released", func() bool {
+		return leader.PlayerState() == youtube.Playing && follower.PlayerState() == youtube.Playing
+	})
+
+	leader.PauseVideo()
+	eventually(t, "the follower left to pause with the leader", func() bool {
+		return follower.PlayerState() == youtube.Paused
+	})
+}
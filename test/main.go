@@ -15,6 +15,7 @@ import (
 	"github.com/gopherjs/vecty/prop"
 
 	"strconv"
+	"time"
 )
 
 const (
@@ -200,20 +201,20 @@ func stat(desc string, value string) *vecty.HTML {
 	)
 }
 
-func (a *TestApp) getVolume() string {
-	return strconv.FormatInt(int64(a.player.Volume()), 10)
+func getVolume(s youtube.PlayerStatus) string {
+	return strconv.FormatInt(int64(s.Volume), 10)
 }
 
-func (a *TestApp) getMuted() string {
-	if a.player.IsMuted() {
+func getMuted(s youtube.PlayerStatus) string {
+	if s.Muted {
 		return "true"
 	}
 	return "false"
 
 }
 
-func (a *TestApp) playerState() string {
-	switch st := a.player.PlayerState(); st {
+func playerState(s youtube.PlayerStatus) string {
+	switch st := s.State; st {
 	case youtube.Unstarted:
 		return "-1 (Unstarted)"
 	case youtube.Ended:
@@ -234,27 +235,27 @@ func (a *TestApp) playerState() string {
 	}
 }
 
-func (a *TestApp) playbackRate() string {
-	return strconv.FormatFloat(a.player.PlaybackRate(), 'f', 2, 64)
+func playbackRate(s youtube.PlayerStatus) string {
+	return strconv.FormatFloat(s.PlaybackRate, 'f', 2, 64)
 }
 
-func (a *TestApp) videoLoadedFraction() string {
-	return strconv.FormatFloat(a.player.VideoLoadedFraction(), 'f', 2, 64)
+func videoLoadedFraction(s youtube.PlayerStatus) string {
+	return strconv.FormatFloat(s.LoadedFraction, 'f', 2, 64)
 }
 
-func (a *TestApp) currentTime() string {
-	return strconv.FormatFloat(a.player.CurrentTime(), 'f', 2, 64)
+func currentTime(s youtube.PlayerStatus) string {
+	return strconv.FormatFloat(s.Position.Seconds(), 'f', 2, 64)
 }
 
-func (a *TestApp) playbackQuality() string {
-	return string(a.player.PlaybackQuality())
+func playbackQuality(s youtube.PlayerStatus) string {
+	return string(s.Quality)
 }
 
-func (a *TestApp) duration() string {
-	return strconv.FormatFloat(a.player.Duration(), 'f', 2, 64)
+func duration(s youtube.PlayerStatus) string {
+	return strconv.FormatFloat(s.Length.Seconds(), 'f', 2, 64)
 }
 
-func (a *TestApp) availableQualityLevels() string {
+func availableQualityLevels(s youtube.PlayerStatus) string {
 	convert := func(qs []youtube.Quality) []string {
 		res := make([]string, 0, len(qs))
 		for _, q := range qs {
@@ -265,35 +266,36 @@ func (a *TestApp) availableQualityLevels() string {
 	return strings.Join(
 		append(
 			[]string{"["},
-			strings.Join(convert(a.player.AvailableQualityLevels()), ", "),
+			strings.Join(convert(s.Qualities), ", "),
 			"]",
 		), " ",
 	)
 
 }
 
-func (a *TestApp) playlist() string {
+func playlist(s youtube.PlayerStatus) string {
 	return strings.Join(
-		append([]string{"["}, strings.Join(a.player.Playlist(), ", "), "]"),
+		append([]string{"["}, strings.Join(s.Playlist, ", "), "]"),
 		" ",
 	)
 }
 
-func (a *TestApp) playlistIndex() string {
-	return strconv.FormatInt(int64(a.player.PlaylistIndex()), 10)
+func playlistIndex(s youtube.PlayerStatus) string {
+	return strconv.FormatInt(int64(s.PlaylistIndex), 10)
 }
 
-func (a *TestApp) videoData() string {
-	data := a.player.VideoData()
+func videoData(s youtube.PlayerStatus) string {
 	return strings.Join([]string{
-		data.Author,
-		data.Title,
-		data.VideoID,
-		string(data.VideoQuality),
+		s.Author,
+		s.Title,
+		s.VideoID,
+		string(s.VideoQuality),
 	}, " | ")
 }
 
 func (a *TestApp) stats() *vecty.HTML {
+	// a single call into JS for every getter
+	s := a.player.Snapshot()
 	return elem.Table(
 		prop.Class("ui padded small red table"),
 		elem.TableHead(
@@ -307,21 +309,21 @@ func (a *TestApp) stats() *vecty.HTML {
 			),
 		),
 		elem.TableBody(
-			stat("Volume()", a.getVolume()),
-			stat("IsMuted()", a.getMuted()),
-			stat("PlayerState()", a.playerState()),
-			stat("PlaybackRate()", a.playbackRate()),
-			stat("VideoLoadedFraction()", a.videoLoadedFraction()),
-			stat("CurrentTime()", a.currentTime()),
-			stat("PlaybackQuality()", a.playbackQuality()),
-			stat("Duration()", a.duration()),
-			stat("VideoURL()", a.player.VideoURL()),
-			stat("VideoEmbedCode()", a.player.VideoEmbedCode()),
-			stat("AvailableQualityLevels()", a.availableQualityLevels()),
-			stat("Playlist()", a.playlist()),
-			stat("PlaylistIndex()", a.playlistIndex()),
+			stat("Volume()", getVolume(s)),
+			stat("IsMuted()", getMuted(s)),
+			stat("PlayerState()", playerState(s)),
+			stat("PlaybackRate()", playbackRate(s)),
+			stat("VideoLoadedFraction()", videoLoadedFraction(s)),
+			stat("CurrentTime()", currentTime(s)),
+			stat("PlaybackQuality()", playbackQuality(s)),
+			stat("Duration()", duration(s)),
+			stat("VideoURL()", s.VideoURL),
+			stat("VideoEmbedCode()", s.EmbedCode),
+			stat("AvailableQualityLevels()", availableQualityLevels(s)),
+			stat("Playlist()", playlist(s)),
+			stat("PlaylistIndex()", playlistIndex(s)),
 			stat("Iframe()", a.player.Iframe().String()),
-			stat("VideoData()", a.videoData()),
+			stat("VideoData()", videoData(s)),
 		),
 	)
 }
@@ -401,14 +403,6 @@ var once = &sync.Once{}
 func (a *TestApp) Render() *vecty.HTML {
 	once.Do(func() {
 		a.selectedQuality = youtube.Large
-		var updFrq int
-		if a.StatUpdateFreq == 0 {
-			updFrq = 500
-		} else {
-			updFrq = a.StatUpdateFreq
-		}
-		// update stat every 1 second
-		js.Global.Call("setInterval", func() { vecty.Rerender(a) }, updFrq) // reset stat
 	})
 
 	return elem.Body(
@@ -430,6 +424,17 @@ func (a *TestApp) rerender() {
 	vecty.Rerender(a)
 }
 
+// observe rerenders the stats when the player's state or the polled values
+// change
+func (a *TestApp) observe() {
+	updFrq := a.StatUpdateFreq
+	if updFrq == 0 {
+		updFrq = 500
+	}
+	a.player.AddEventListener(youtube.OnStateChange, func(*youtube.Event) { a.rerender() })
+	a.player.Observe(time.Duration(updFrq)*time.Millisecond, func(youtube.TypedEvent) { a.rerender() })
+}
+
 // -----------
 
 func init() {
@@ -470,5 +475,6 @@ func main() {
 
 		app.player = youtube.NewPlayer(playerID, props)
 		app.showStat = true
+		app.observe()
 	})
 }
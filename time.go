@@ -0,0 +1,79 @@
+package youtube
+
+import (
+	"time"
+
+	"github.com/iocat/youtube/yttype"
+)
+
+// SeekToDuration seeks to d. See SeekTo for allowSeekAhead.
+func (p *Player) SeekToDuration(d time.Duration, allowSeekAhead bool) {
+	p.SeekTo(d.Seconds(), allowSeekAhead)
+}
+
+// Position returns the elapsed time of the video, as CurrentTime
+func (p *Player) Position() time.Duration {
+	return yttype.Seconds(p.CurrentTime())
+}
+
+// Length returns the duration of the video, as Duration. It is 0 until the
+// metadata of the video is loaded.
+func (p *Player) Length() time.Duration {
+	return yttype.Seconds(p.Duration())
+}
+
+// LoadVideoByIDAt loads the video with the given ID and starts it at start
+func (p *Player) LoadVideoByIDAt(vid string, start time.Duration, q Quality) {
+	p.LoadVideoByID(vid, start.Seconds(), q)
+}
+
+// CueVideoByIDAt cues the video with the given ID to start at start
+func (p *Player) CueVideoByIDAt(vid string, start time.Duration, q Quality) {
+	p.CueVideoByID(vid, start.Seconds(), q)
+}
+
+func (s *SafePlayer) SeekToDuration(d time.Duration, allowSeekAhead bool) error {
+	return s.SeekTo(d.Seconds(), allowSeekAhead)
+}
+
+func (s *SafePlayer) Position() (time.Duration, error) {
+	t, err := s.CurrentTime()
+	return yttype.Seconds(t), err
+}
+
+func (s *SafePlayer) Length() (time.Duration, error) {
+	d, err := s.Duration()
+	return yttype.Seconds(d), err
+}
+
+// SetRange plays the video from start to end. A zero end plays it to the end.
+func (o *LoadByIDOptions) SetRange(start, end time.Duration) {
+	o.StartSeconds = start.Seconds()
+	if end > 0 {
+		o.EndSeconds = end.Seconds()
+	}
+}
+
+// SetRange plays the video from start to end. A zero end plays it to the end.
+func (o *LoadByURLOptions) SetRange(start, end time.Duration) {
+	o.StartSeconds = start.Seconds()
+	if end > 0 {
+		o.EndSeconds = end.Seconds()
+	}
+}
+
+// SetStart starts the first video of the playlist at start
+func (o *CuePlaylistOptions) SetStart(start time.Duration) {
+	o.StartSeconds = start.Seconds()
+}
+
+// SetRange sets the start and end parameters, which the player takes in whole
+// seconds: start is rounded down and end up, so the range is never shortened.
+// A zero end plays the video to the end.
+func (pp *PlayerParams) SetRange(start, end time.Duration) {
+	startSecs, endSecs := yttype.RangeSeconds(start, end)
+	pp.Start = startSecs
+	if end > 0 {
+		pp.End = endSecs
+	}
+}
@@ -0,0 +1,325 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iocat/youtube/yturl"
+)
+
+// Issue is a problem found by Properties.Validate
+type Issue struct {
+	// Param is the name of the parameter, as in the player_parameters
+	// documentation, or videoId, width and height
+	Param string
+	// Warning is set for parameters the player accepts but ignores, such as
+	// deprecated ones. Other issues make the player fail or misbehave.
+	Warning bool
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Warning {
+		return i.Param + ": warning: " + i.Message
+	}
+	return i.Param + ": " + i.Message
+}
+
+// ValidationError is returned by Properties.Validate. It lists every issue,
+// warnings included.
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		msgs[i] = issue.String()
+	}
+	return "youtube: invalid properties: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the properties and their player parameters against the
+// documented values. It returns a *ValidationError if any issue is not a
+// warning, and nil otherwise; use Issues to get the warnings as well.
+func (props *Properties) Validate() error {
+	issues := props.Issues()
+	for _, issue := range issues {
+		if !issue.Warning {
+			return &ValidationError{Issues: issues}
+		}
+	}
+	return nil
+}
+
+// Issues returns every issue of the properties, sorted by parameter
+func (props *Properties) Issues() []Issue {
+	var raw struct {
+		Width      interface{}            `json:"width"`
+		Height     interface{}            `json:"height"`
+		VideoID    interface{}            `json:"videoId"`
+		PlayerVars map[string]interface{} `json:"playerVars"`
+	}
+	if err := json.Unmarshal([]byte(props.paramsJSON()), &raw); err != nil {
+		return []Issue{{Param: "properties", Message: err.Error()}}
+	}
+	// the top level properties are checked along with the parameters
+	all := make(params, len(raw.PlayerVars)+3)
+	for k, v := range raw.PlayerVars {
+		all[k] = v
+	}
+	for k, v := range map[string]interface{}{"width": raw.Width, "height": raw.Height, "videoId": raw.VideoID} {
+		if v != nil {
+			all[k] = v
+		}
+	}
+
+	var issues []Issue
+	for name := range all {
+		if _, known := paramRules[name]; !known {
+			issues = append(issues, Issue{Param: name, Warning: true, Message: "unknown parameter"})
+		}
+	}
+	for name, r := range paramRules {
+		v, ok := all[name]
+		if !ok {
+			continue
+		}
+		if msg := r.check(v); msg != "" {
+			issues = append(issues, Issue{Param: name, Warning: r.warning, Message: msg})
+		}
+	}
+	for _, r := range crossRules {
+		if msg := r.check(all); msg != "" {
+			issues = append(issues, Issue{Param: r.param, Warning: r.warning, Message: msg})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Param < issues[j].Param })
+	return issues
+}
+
+// params are decoded player parameters
+type params map[string]interface{}
+
+// int returns the integer value of a parameter, whether it was given as a
+// number or a string
+func (p params) int(name string) (int, bool) {
+	switch v := p[name].(type) {
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), true
+		}
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func (p params) str(name string) string {
+	s, _ := p[name].(string)
+	return s
+}
+
+func (p params) has(name string) bool {
+	_, ok := p[name]
+	return ok
+}
+
+// paramRule checks the value of one parameter
+type paramRule struct {
+	warning bool
+	check   func(v interface{}) string
+}
+
+// crossRule checks a combination of parameters
+type crossRule struct {
+	param   string
+	warning bool
+	check   func(p params) string
+}
+
+// paramRules are the rules of every known parameter. Parameters missing from
+// the table are reported as unknown.
+var paramRules = map[string]paramRule{
+	"videoId": {check: func(v interface{}) string {
+		if id, _ := v.(string); !yturl.ValidVideoID(id) {
+			return fmt.Sprintf("%v must be 11 characters of A-Z, a-z, 0-9, - and _", v)
+		}
+		return ""
+	}},
+	"width":           {check: nonNegative},
+	"height":          {check: nonNegative},
+	"autoplay":        {check: oneOf(0, 1)},
+	"cc_load_policy":  {check: oneOf(0, 1)},
+	"cc_lang_pref":    {check: language},
+	"color":           {check: oneOfStrings(string(Red), string(White))},
+	"controls":        {check: oneOf(0, 1, 2)},
+	"disablekb":       {check: oneOf(0, 1)},
+	"enablejsapi":     {check: oneOf(0, 1)},
+	"end":             {check: nonNegative},
+	"fs":              {check: oneOf(0, 1)},
+	"hl":              {check: language},
+	"iv_load_policy":  {check: oneOf(IvPolicyShown, IvPolicyNotShown)},
+	"list":            {check: nonEmptyString},
+	"listType":        {check: oneOfStrings(string(ListTypePlaylist), string(ListTypeSearch), string(ListTypeUserUploads))},
+	"loop":            {check: oneOf(0, 1)},
+	"mute":            {check: oneOf(0, 1)},
+	"origin":          {check: origin},
+	"playlist":        {check: playlist},
+	"playsinline":     {check: oneOf(0, 1)},
+	"rel":             {check: oneOf(0, 1)},
+	"start":           {check: nonNegative},
+	"widget_referrer": {check: absoluteURL},
+	"modestbranding":  {warning: true, check: deprecated},
+	"showinfo":        {warning: true, check: deprecated},
+	"autohide":        {warning: true, check: deprecated},
+	"theme":           {warning: true, check: deprecated},
+}
+
+var crossRules = []crossRule{
+	{param: "end", check: func(p params) string {
+		start, _ := p.int("start")
+		if end, ok := p.int("end"); ok && end > 0 && end <= start {
+			return fmt.Sprintf("end %d is not after start %d", end, start)
+		}
+		return ""
+	}},
+	{param: "listType", check: func(p params) string {
+		if p.has("listType") && p.str("list") == "" {
+			return "listType needs a list"
+		}
+		return ""
+	}},
+	{param: "list", check: func(p params) string {
+		lt := p.str("listType")
+		if list := p.str("list"); list != "" && (lt == "" || lt == string(ListTypePlaylist)) && !yturl.ValidPlaylistID(list) {
+			return fmt.Sprintf("%q is not a playlist ID", list)
+		}
+		return ""
+	}},
+	{param: "loop", check: func(p params) string {
+		if loop, _ := p.int("loop"); loop == 1 && !p.has("playlist") && p.str("list") == "" {
+			return "loop needs a playlist: set playlist to the video ID to loop a single video"
+		}
+		return ""
+	}},
+	{param: "listType", warning: true, check: func(p params) string {
+		if p.str("listType") == string(ListTypeSearch) {
+			return "search is deprecated"
+		}
+		return ""
+	}},
+	{param: "controls", warning: true, check: func(p params) string {
+		if controls, _ := p.int("controls"); controls == 2 {
+			return "2 is deprecated and behaves like 1"
+		}
+		return ""
+	}},
+	{param: "origin", warning: true, check: func(p params) string {
+		if api, _ := p.int("enablejsapi"); p.has("origin") && api != 1 {
+			return "origin only applies with enablejsapi=1"
+		}
+		return ""
+	}},
+}
+
+func oneOf(values ...int) func(interface{}) string {
+	return func(v interface{}) string {
+		n, ok := params{"v": v}.int("v")
+		if ok {
+			for _, value := range values {
+				if n == value {
+					return ""
+				}
+			}
+		}
+		strs := make([]string, len(values))
+		for i, value := range values {
+			strs[i] = fmt.Sprint(value)
+		}
+		return fmt.Sprintf("%v must be one of %s", v, strings.Join(strs, ", "))
+	}
+}
+
+func oneOfStrings(values ...string) func(interface{}) string {
+	return func(v interface{}) string {
+		s, _ := v.(string)
+		for _, value := range values {
+			if s == value {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%v must be one of %s", v, strings.Join(values, ", "))
+	}
+}
+
+func nonNegative(v interface{}) string {
+	if n, ok := (params{"v": v}).int("v"); !ok || n < 0 {
+		return fmt.Sprintf("%v must be a non-negative integer", v)
+	}
+	return ""
+}
+
+func nonEmptyString(v interface{}) string {
+	if s, _ := v.(string); s == "" {
+		return "must not be empty"
+	}
+	return ""
+}
+
+func language(v interface{}) string {
+	s, _ := v.(string)
+	if len(s) < 2 || len(s) > 8 {
+		return fmt.Sprintf("%v must be a language code such as en or pt-BR", v)
+	}
+	return ""
+}
+
+func origin(v interface{}) string {
+	s, _ := v.(string)
+	if _, ok := yturl.NormalizeOrigin(s); !ok {
+		return fmt.Sprintf("%q must be a scheme and host, such as https://example.com", s)
+	}
+	return ""
+}
+
+func playlist(v interface{}) string {
+	var ids []interface{}
+	switch v := v.(type) {
+	case []interface{}:
+		ids = v
+	case string:
+		// the player also takes a comma-separated list
+		for _, id := range strings.Split(v, ",") {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return "must list video IDs"
+	}
+	for _, id := range ids {
+		if s, _ := id.(string); !yturl.ValidVideoID(s) {
+			return fmt.Sprintf("%v is not a video ID", id)
+		}
+	}
+	return ""
+}
+
+func absoluteURL(v interface{}) string {
+	s, _ := v.(string)
+	if u, err := url.Parse(s); err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Sprintf("%q must be an absolute URL", s)
+	}
+	return ""
+}
+
+func deprecated(interface{}) string {
+	return "deprecated, the player ignores it"
+}
@@ -0,0 +1,123 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrDestroyed is returned by the waits of a player that has been
+	// destroyed
+	ErrDestroyed = errors.New("youtube: player destroyed")
+	// ErrEnded is returned by WaitUntilTime when the video ends first
+	ErrEnded = errors.New("youtube: video ended")
+)
+
+// waitTick is how often WaitUntilTime polls the current time
+const waitTick = 250 * time.Millisecond
+
+// WaitReady blocks until the player fires onReady. It returns ErrDestroyed if
+// the player is destroyed first, or ctx.Err() if ctx is done first.
+//
+// Only players created with NewPlayer report onReady. Any other player is
+// marked ready if the API installed its methods, which it does once the
+// player is ready, and otherwise WaitReady returns an error wrapping
+// ErrNotReady rather than blocking.
+func (p *Player) WaitReady(ctx context.Context) error {
+	st := p.state()
+	st.mu.Lock()
+	tracked := st.tracked
+	st.mu.Unlock()
+	if !tracked && st.Lifecycle() == LifecycleCreating {
+		if !isFunction(p.Get("getPlayerState")) {
+			return fmt.Errorf("%w: the player was not created with NewPlayer", ErrNotReady)
+		}
+		st.MarkReady()
+	}
+	return st.WaitReady(ctx)
+}
+
+// WaitForState blocks until the player reaches the given state. It returns
+// immediately if the player is already in that state. Like WaitReady, it
+// waits for onReady first.
+func (p *Player) WaitForState(ctx context.Context, state PlayerState) error {
+	return p.wait(ctx, 0, func(ev TypedEvent) bool {
+		if sc, ok := ev.(StateChangeEvent); ok {
+			return sc.State == state
+		}
+		return ev == nil && p.PlayerState() == state
+	}, OnStateChange)
+}
+
+// WaitUntilTime blocks until the current playback time reaches t. It returns
+// ErrEnded if the video ends before. Like WaitReady, it waits for onReady
+// first.
+func (p *Player) WaitUntilTime(ctx context.Context, t time.Duration) error {
+	var ended bool
+	err := p.wait(ctx, waitTick, func(ev TypedEvent) bool {
+		if p.CurrentTime() >= t.Seconds() {
+			return true
+		}
+		state := p.PlayerState()
+		if sc, ok := ev.(StateChangeEvent); ok {
+			state = sc.State
+		}
+		ended = state == Ended
+		return ended
+	}, OnStateChange)
+	if err == nil && ended {
+		return ErrEnded
+	}
+	return err
+}
+
+// wait blocks until the player is ready and done reports true. done is called
+// with nil once the player is ready and on every tick, and with the event on
+// every event of the given types. An onError event, returned as a
+// *PlayerError, or the destruction of the player aborts the wait.
+func (p *Player) wait(ctx context.Context, tick time.Duration, done func(TypedEvent) bool, types ...EventType) error {
+	if err := p.WaitReady(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := p.Events(ctx, append(types, OnError)...)
+	if done(nil) {
+		return nil
+	}
+
+	var tickC <-chan time.Time
+	if tick > 0 {
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		tickC = ticker.C
+	}
+	st := p.state()
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				if st.isDestroyed() {
+					return ErrDestroyed
+				}
+				return ctx.Err()
+			}
+			if e, isErr := ev.(ErrorEvent); isErr {
+				return e.PlayerError()
+			}
+			if done(ev) {
+				return nil
+			}
+		case <-tickC:
+			if done(nil) {
+				return nil
+			}
+		case <-st.destroyed:
+			return ErrDestroyed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -1,8 +1,6 @@
 package youtube
 
-import (
-	"github.com/gopherjs/gopherjs/js"
-)
+import "github.com/iocat/youtube/yttype"
 
 type ProgessBarColor string
 
@@ -11,17 +9,18 @@ const (
 	White ProgessBarColor = "white"
 )
 
-type ControlsMode int
+// ControlsMode is the controls player parameter
+type ControlsMode = yttype.ControlsMode
 
 const (
 	// ControlsNotDisplay has the player controls not displayed in the player
-	ControlsNotDisplay ControlsMode = iota
+	ControlsNotDisplay = yttype.ControlsNotDisplay
 	// ControlsDisplayImmediately has the controls displayed immediately and
 	// the Flash player also loads immediately.
-	ControlsDisplayImmediately
+	ControlsDisplayImmediately = yttype.ControlsDisplayImmediately
 	// ControlsDisplayAfter has the controls displayed, and the Flash player
 	// loaded after the user initiates the video playback.
-	ControlsDisplayAfter
+	ControlsDisplayAfter = yttype.ControlsDisplayAfter
 )
 
 const (
@@ -32,330 +31,201 @@ const (
 	IvPolicyNotShown
 )
 
-type ListType string
+// ListType is the listType player parameter
+type ListType = yttype.ListType
 
 const (
 	// ListTypePlaylist represents playlist
-	ListTypePlaylist ListType = "playlist"
+	ListTypePlaylist = yttype.ListTypePlaylist
 	// ListTypeSearch represents search list
-	ListTypeSearch ListType = "search"
+	ListTypeSearch = yttype.ListTypeSearch
 	// ListTypeUserUploads represents user uploads
-	ListTypeUserUploads ListType = "user_uploads"
+	ListTypeUserUploads = yttype.ListTypeUserUploads
 )
 
 // Quality represents the player video's quality
-type Quality string
+type Quality = yttype.Quality
 
 const (
-	Small   Quality = "small"
-	Medium  Quality = "medium"
-	Large   Quality = "large"
-	HD720   Quality = "hd720"
-	HD1080  Quality = "hd1080"
-	HighRes Quality = "highres"
+	Tiny    = yttype.Tiny
+	Small   = yttype.Small
+	Medium  = yttype.Medium
+	Large   = yttype.Large
+	HD720   = yttype.HD720
+	HD1080  = yttype.HD1080
+	HD1440  = yttype.HD1440
+	HD2160  = yttype.HD2160
+	HighRes = yttype.HighRes
+	Auto    = yttype.Auto
 )
 
-// Error represents the errors returned OnError event
-type Error int
+// Error represents the errors returned OnError event. It is a Go error.
+type Error = yttype.Error
 
 const (
-	ErrInvalidParameters Error = 2
-	ErrNonHTML5          Error = 5
-	ErrVideoNotFound     Error = 100
-	ErrNotForEmbedded    Error = 101
-	err101InDisguise     Error = 150
+	ErrInvalidParameters = yttype.ErrInvalidParameters
+	ErrNonHTML5          = yttype.ErrNonHTML5
+	ErrVideoNotFound     = yttype.ErrVideoNotFound
+	ErrNotForEmbedded    = yttype.ErrNotForEmbedded
+	// ErrNotForEmbeddedInDisguise is the same error as ErrNotForEmbedded:
+	// errors.Is reports either one for both codes.
+	ErrNotForEmbeddedInDisguise = yttype.ErrNotForEmbeddedInDisguise
 )
 
-func (err Error) String() string {
-	switch err {
-	case ErrInvalidParameters:
-		return `The request contains an invalid parameter value.`
-	case ErrNonHTML5:
-		return `The requested content cannot be played in an HTML5 player or another error related to the HTML5 player has occurred.`
-	case ErrVideoNotFound:
-		return "The video requested was not found."
-	case ErrNotForEmbedded:
-		return "The owner of the requested video does not allow it to be played in embedded players."
-	default:
-		return "unknown"
-	}
-}
+// PlayerError is an Error along with what the player was playing when it
+// occurred. The waits return it when the player fires onError.
+type PlayerError = yttype.PlayerError
 
 // PlayerState represents the youtube player's state
-type PlayerState int
+type PlayerState = yttype.PlayerState
 
 const (
-	Unstarted PlayerState = iota - 1
-	Ended
-	Playing
-	Paused
-	Buffering
-	_
-	VideoCued
+	Unstarted = yttype.Unstarted
+	Ended     = yttype.Ended
+	Playing   = yttype.Playing
+	Paused    = yttype.Paused
+	Buffering = yttype.Buffering
+	VideoCued = yttype.VideoCued
 )
 
-type EventType string
+// EventType is the name of a player event
+type EventType = yttype.EventType
 
 const (
-	OnReady                 EventType = "onReady"
-	OnStateChange           EventType = "onStateChange"
-	OnPlaybackQualityChange EventType = "onPlaybackQualityChange"
-	OnPlaybackRateChange    EventType = "onPlaybackRateChange"
-	OnError                 EventType = "onError"
-	OnApiChange             EventType = "onApiChange"
+	OnReady                 = yttype.OnReady
+	OnStateChange           = yttype.OnStateChange
+	OnPlaybackQualityChange = yttype.OnPlaybackQualityChange
+	OnPlaybackRateChange    = yttype.OnPlaybackRateChange
+	OnError                 = yttype.OnError
+	OnApiChange             = yttype.OnApiChange
 )
 
-type Event struct {
-	*js.Object
-	Target *Player    `js:"target"`
-	Data   *js.Object `js:"data"`
-}
-
-// Player represents the Youtube Iframe player
-type Player struct {
-	*js.Object
-}
-
-// Properties represents a set of video properties feeded to NewPlayer(id, properties)
-// to create the player. NewProperties() is recommended to create the properties.
-type Properties struct {
-	*js.Object
-	Width      int           `js:"width"`
-	Height     int           `js:"height"`
-	VideoID    string        `js:"videoId"`
-	PlayerVars *PlayerParams `js:"playerVars"`
-	Events     *PlayerEvents `js:"events"`
-}
-
-func newObj() *js.Object {
-	return js.Global.Get("Object").New()
-}
-
-// NewProperties creates a new Property JS object
-// with all inner objects properly initialized
-func NewProperties() *Properties {
-	props := &Properties{Object: newObj()}
-	vars := &PlayerParams{Object: newObj()}
-	eves := &PlayerEvents{Object: newObj()}
-	props.PlayerVars = vars
-	props.Events = eves
-	return props
-}
-
-// PlayerEvents contains a set of callbacks assigned at the creation of the
-// player. This struct's fields correspond to each youtube.EventType
-type PlayerEvents struct {
-	*js.Object
-	OnReady                 func(*Event) `js:"onReady"`
-	OnStateChange           func(*Event) `js:"onStateChange"`
-	OnPlaybackQualityChange func(*Event) `js:"onPlaybackQualityChange"`
-	OnPlaybackRateChange    func(*Event) `js:"onPlaybackRateChange"`
-	OnError                 func(*Event) `js:"onError"`
-	OnAPIChange             func(*Event) `js:"onApiChange"`
-}
-
-// PlayerParams represents the player parameter documented at
-// https://developers.google.com/youtube/player_parameters
-type PlayerParams struct {
-	*js.Object
-	Autoplay       int             `js:"autoplay"`
-	CcLoadPolicy   int             `js:"cc_load_policy"`
-	Color          ProgessBarColor `js:"color"`
-	Controls       ControlsMode    `js:"controls"`
-	DisableKB      int             `js:"disablekb"`
-	EnableJsAPI    int             `js:"enablejsapi"`
-	End            int             `js:"end"`
-	Fs             int             `js:"fs"`
-	Hl             string          `js:"hl"`
-	IvLoadPolicy   int             `js:"iv_load_policy"`
-	List           string          `js:"list"`
-	ListType       ListType        `js:"listType"`
-	Loop           int             `js:"loop"`
-	ModestBranding int             `js:"modestbranding"`
-	Origin         string          `js:"origin"`
-	Playlist       []string        `js:"playlist"`
-	PlaysInline    int             `js:"playsinline"`
-	Rel            int             `js:"rel"`
-	ShowInfo       int             `js:"showinfo"`
-	Start          int             `js:"start"`
-	WidgetReferrer string          `js:"widget_referrer"`
-}
-
-// LoadByIDOptions represents an argument for Player.LoadVideoByID2(arg)
-// and Player.CueVideoByID2(arg)
-type LoadByIDOptions struct {
-	*js.Object
-	VideoID          string  `js:"videoId"`
-	StartSeconds     float64 `js:"startSeconds"`
-	EndSeconds       float64 `js:"endSeconds"`
-	SuggestedQuality Quality `js:"suggestedQuality"`
-}
-
-// NewLoadByIDOptions prepares an argument for
-// Player.LoadVideoByID2(arg) and Player.CueVideoByID2(arg)
-// with all inner objects properly initialized
-func NewLoadByIDOptions() *LoadByIDOptions {
-	return &LoadByIDOptions{
-		Object: newObj(),
-	}
-}
-
-// LoadByURLOptions represents an argument for Player.LoadVideoByUrl2(arg)
-type LoadByURLOptions struct {
-	*js.Object
-	MediaContentURL  string  `js:"mediaContentUrl"`
-	StartSeconds     float64 `js:"startSeconds"`
-	EndSeconds       float64 `js:"endSeconds"`
-	SuggestedQuality Quality `js:"suggestedQuality"`
-}
-
-// NewLoadByURLOptions returns a prepared argument for Player.LoadeVideoByUrl2(arg)
-// with all inner objects properly initialized
-func NewLoadByURLOptions() *LoadByURLOptions {
-	return &LoadByURLOptions{
-		Object: newObj(),
-	}
-}
-
-// CuePlaylistOptions represents an argument for Player.CuePlaylist2(arg)
-type CuePlaylistOptions struct {
-	*js.Object
-	ListType ListType `js:"listType"`
-	// the ID of the list
-	List             string  `js:"list"`
-	Index            int     `js:"index"`
-	StartSeconds     float64 `js:"startSeconds"`
-	SuggestedQuality Quality `js:"suggestedQuality"`
-}
-
-// NewCuePlaylistOptions prepares an argument for Player.CuePlaylist2(arg)
-// with all inner objects properly initialized
-func NewCuePlaylistOptions() *CuePlaylistOptions {
-	return &CuePlaylistOptions{
-		Object: newObj(),
-	}
-}
-
-// NewPlayer creates a new youtube player by replacing the
-// provided iframe with the id of iframeId
-// This call is equivalent to new YT.Player(id, props)
-func NewPlayer(iframeID string, props *Properties) *Player {
-	np := js.Global.Get("YT").Get("Player").New(iframeID, props.Object)
-
-	return &Player{
-		Object: np,
-	}
-}
-
 // UPDATE PLAYER CONTENT FUNCTIONS
 
 func (p *Player) LoadVideoByID(vid string, startSec float64, q Quality) {
-	p.Call("loadVideoById", vid, startSec, q)
+	p.command("loadVideoById", vid, startSec, string(q))
 }
 
 func (p *Player) LoadVideoByID2(params *LoadByIDOptions) {
-	p.Call("loadVideoById", params)
+	p.command("loadVideoById", params.value())
 }
 
 func (p *Player) CueVideoByID(vid string, startSec float64, q Quality) {
-	p.Call("cueVideoById", vid, startSec, q)
+	p.command("cueVideoById", vid, startSec, string(q))
 }
 
 func (p *Player) CueVideoByID2(params *LoadByIDOptions) {
-	p.Call("cueVideoById", params)
+	p.command("cueVideoById", params.value())
 }
 
 func (p *Player) LoadVideoByURL(url string, startSec float64, q Quality) {
-	p.Call("loadVideoByUrl", url, startSec, q)
+	p.command("loadVideoByUrl", url, startSec, string(q))
 }
 
 func (p *Player) LoadVideoByURL2(params *LoadByURLOptions) {
-	p.Call("loadVideoByUrl", params)
+	p.command("loadVideoByUrl", params.value())
 }
 
 func (p *Player) CuePlaylist(ids []string, index int, startSec float64, q Quality) {
-	p.Call("cuePlaylist", ids, index, startSec, q)
+	p.command("cuePlaylist", stringArray(ids), index, startSec, string(q))
 }
 
 func (p *Player) CuePlaylist2(params *CuePlaylistOptions) {
-	p.Call("cuePlaylist", params)
+	p.command("cuePlaylist", params.value())
 }
 
 func (p *Player) LoadPlaylist(ids []string, index int, startSec float64, q Quality) {
-	p.Call("cuePlaylist", ids, index, startSec, q)
+	p.command("loadPlaylist", stringArray(ids), index, startSec, string(q))
 }
 
 func (p *Player) LoadPlaylist2(params *CuePlaylistOptions) {
-	p.Call("cuePlaylist", params)
+	p.command("loadPlaylist", params.value())
 }
 
 // Playback controls and player settings
 
 func (p *Player) PlayVideo() {
-	p.Call("playVideo")
+	p.command("playVideo")
 }
 
 func (p *Player) PauseVideo() {
-	p.Call("pauseVideo")
+	p.command("pauseVideo")
 }
 
 func (p *Player) StopVideo() {
-	p.Call("stopVideo")
+	p.command("stopVideo")
 }
 
 func (p *Player) SeekTo(seconds float64, allowSeekAhead bool) {
-	p.Call("seekTo", seconds, allowSeekAhead)
+	p.command("seekTo", seconds, allowSeekAhead)
 }
 
 func (p *Player) NextVideo() {
-	p.Call("nextVideo")
+	p.command("nextVideo")
 }
 
 func (p *Player) PreviousVideo() {
-	p.Call("previousVideo")
+	p.command("previousVideo")
 }
 
 func (p *Player) PlayVideoAt(index int) {
-	p.Call("playVideoAt", index)
+	p.command("playVideoAt", index)
 }
 
 func (p *Player) Mute() {
-	p.Call("mute")
+	p.command("mute")
 }
 
 func (p *Player) UnMute() {
-	p.Call("unMute")
+	p.command("unMute")
 }
 
 func (p *Player) IsMuted() bool {
+	if p.destroyed() {
+		return false
+	}
 	return p.Call("isMuted").Bool()
 }
 
 func (p *Player) SetVolume(vol int) {
-	p.Call("setVolume", vol)
+	p.command("setVolume", vol)
 }
 
 func (p *Player) Volume() int {
+	if p.destroyed() {
+		return 0
+	}
 	return p.Call("getVolume").Int()
 }
 
-func (p *Player) SetSize(width int, height int) *js.Object {
+// SetSize resizes the iframe and returns the result of the JS call, which is
+// undefined once the player is destroyed
+func (p *Player) SetSize(width int, height int) JSValue {
+	if p.destroyed() {
+		return undefined()
+	}
 	return p.Call("setSize", width, height)
 }
 
 func (p *Player) PlaybackRate() float64 {
+	if p.destroyed() {
+		return 0
+	}
 	return p.Call("getPlaybackRate").Float()
 }
 
 func (p *Player) SetPlaybackRate(suggestedRate float64) {
-	p.Call("setPlaybackRate", suggestedRate)
+	p.command("setPlaybackRate", suggestedRate)
 }
 
 // AvailableRates returns the set of playback rates in which the current video
 // is available
 func (p *Player) AvailablePlaybackRates() []float64 {
+	if p.destroyed() {
+		return nil
+	}
 	rates := p.Call("getAvailablePlaybackRates")
-	if rates == js.Undefined || rates == nil {
+	if isNullish(rates) {
 		return nil
 	}
 	length := rates.Length()
@@ -367,36 +237,53 @@ func (p *Player) AvailablePlaybackRates() []float64 {
 }
 
 func (p *Player) SetLoop(val bool) {
-	p.Call("setLoop", val)
+	p.command("setLoop", val)
 }
 
 func (p *Player) SetShuffle(val bool) {
-	p.Call("shufflePlaylist", val)
+	p.command("setShuffle", val)
 }
 
 func (p *Player) VideoLoadedFraction() float64 {
+	if p.destroyed() {
+		return 0
+	}
 	return p.Call("getVideoLoadedFraction").Float()
 }
 
+// PlayerState returns the state of the player, Unstarted once it is
+// destroyed
 func (p *Player) PlayerState() PlayerState {
+	if p.destroyed() {
+		return Unstarted
+	}
 	return PlayerState(p.Call("getPlayerState").Int())
 }
 
 func (p *Player) CurrentTime() float64 {
+	if p.destroyed() {
+		return 0
+	}
 	return p.Call("getCurrentTime").Float()
 }
 
 func (p *Player) PlaybackQuality() Quality {
+	if p.destroyed() {
+		return ""
+	}
 	return Quality(p.Call("getPlaybackQuality").String())
 }
 
 func (p *Player) SetPlaybackQuality(suggested Quality) {
-	p.Call("setPlaybackQuality", suggested)
+	p.command("setPlaybackQuality", string(suggested))
 }
 
 func (p *Player) AvailableQualityLevels() []Quality {
+	if p.destroyed() {
+		return nil
+	}
 	aql := p.Call("getAvailableQualityLevels")
-	if aql == js.Undefined || aql == nil {
+	if isNullish(aql) {
 		return nil
 	}
 	q := make([]Quality, 0, aql.Length())
@@ -407,22 +294,34 @@ func (p *Player) AvailableQualityLevels() []Quality {
 }
 
 func (p *Player) Duration() float64 {
+	if p.destroyed() {
+		return 0
+	}
 	return p.Call("getDuration").Float()
 }
 
 func (p *Player) VideoURL() string {
+	if p.destroyed() {
+		return ""
+	}
 	return p.Call("getVideoUrl").String()
 }
 
 func (p *Player) VideoEmbedCode() string {
+	if p.destroyed() {
+		return ""
+	}
 	return p.Call("getVideoEmbedCode").String()
 }
 
 // Retrieve Playlist Info
 
 func (p *Player) Playlist() []string {
+	if p.destroyed() {
+		return nil
+	}
 	ids := p.Call("getPlaylist")
-	if ids == js.Undefined || ids == nil {
+	if isNullish(ids) {
 		return nil
 	}
 	res := make([]string, 0, ids.Length())
@@ -433,35 +332,41 @@ func (p *Player) Playlist() []string {
 }
 
 func (p *Player) PlaylistIndex() int {
+	if p.destroyed() {
+		return 0
+	}
 	return p.Call("getPlaylistIndex").Int()
 }
 
-func (p *Player) AddEventListener(event EventType, listener func(event *Event)) {
-	p.Call("addEventListener", listener)
-}
-
-func (p *Player) RemoveEventListener(event EventType, listener func(event *Event)) {
-	p.Call("removeEventListener", listener)
-}
-
-func (p *Player) Iframe() *js.Object {
+// Iframe returns the iframe of the player, undefined once it is destroyed
+func (p *Player) Iframe() JSValue {
+	if p.destroyed() {
+		return undefined()
+	}
 	return p.Call("getIframe")
 }
 
-func (p *Player) Destroy() {
-	p.Call("destroy")
+// destroyed reports whether the player was destroyed. Its getters then return
+// zero values rather than call the JS player, whose methods are gone.
+func (p *Player) destroyed() bool {
+	return p.state().isDestroyed()
 }
 
-type VideoData struct {
-	*js.Object
-	VideoID      string  `js:"video_id"`
-	Author       string  `js:"author"`
-	Title        string  `js:"title"`
-	VideoQuality Quality `js:"video_quality"`
+// emptyVideoData returns the object VideoData decodes once the player is
+// destroyed
+func emptyVideoData() JSValue {
+	obj := newObj()
+	for _, key := range []string{"video_id", "author", "title", "video_quality"} {
+		obj.Set(key, "")
+	}
+	return obj
 }
 
-func (p *Player) VideoData() *VideoData {
-	return &VideoData{
-		Object: p.Call("getVideoData"),
+// stringArray converts ids into a value both JS backends pass as an array
+func stringArray(ids []string) []interface{} {
+	arr := make([]interface{}, len(ids))
+	for i, id := range ids {
+		arr[i] = id
 	}
+	return arr
 }
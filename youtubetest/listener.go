@@ -0,0 +1,80 @@
+package youtubetest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/iocat/youtube"
+)
+
+type listener struct {
+	once   sync.Once
+	fn     func(*youtube.Event)
+	remove func()
+}
+
+func (l *listener) Remove() {
+	l.once.Do(l.remove)
+}
+
+// AddEventListener adds a listener called by Emit for events of the given type
+func (p *Player) AddEventListener(event youtube.EventType, fn func(event *youtube.Event)) youtube.Listener {
+	l := &listener{fn: fn}
+	l.remove = func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		ls := p.listeners[event]
+		for i, o := range ls {
+			if o == l {
+				p.listeners[event] = append(ls[:i:i], ls[i+1:]...)
+				return
+			}
+		}
+	}
+	p.mu.Lock()
+	p.record("AddEventListener", event)
+	p.listeners[event] = append(p.listeners[event], l)
+	p.mu.Unlock()
+	return l
+}
+
+// RemoveEventListener removes a listener added with AddEventListener
+func (p *Player) RemoveEventListener(l youtube.Listener) {
+	p.mu.Lock()
+	p.record("RemoveEventListener")
+	p.mu.Unlock()
+	if l != nil {
+		l.Remove()
+	}
+}
+
+// Subscribe calls fn synchronously with every emitted event of the given
+// types, or of every type if none is given. Unlike AddEventListener, it needs
+// no JS environment. The returned func unsubscribes.
+func (p *Player) Subscribe(fn func(youtube.TypedEvent), types ...youtube.EventType) (cancel func()) {
+	return p.hub.Subscribe(fn, types...)
+}
+
+// Events returns a channel of the emitted events of the given types, or of
+// every type if none is given. Like the real player's stream, it drops the
+// oldest event when the receiver falls behind, and is closed once ctx is done
+// or the double is destroyed.
+func (p *Player) Events(ctx context.Context, types ...youtube.EventType) <-chan youtube.TypedEvent {
+	return p.hub.Events(ctx, types...)
+}
+
+// newEvent builds the raw event the bindings would receive for ev
+func newEvent(ev youtube.TypedEvent) *youtube.Event {
+	var data interface{}
+	switch ev := ev.(type) {
+	case youtube.StateChangeEvent:
+		data = int(ev.State)
+	case youtube.QualityChangeEvent:
+		data = string(ev.Quality)
+	case youtube.RateChangeEvent:
+		data = ev.Rate
+	case youtube.ErrorEvent:
+		data = int(ev.Err)
+	}
+	return youtube.NewEvent(nil, data)
+}
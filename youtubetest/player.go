@@ -0,0 +1,498 @@
+// Package youtubetest provides a test double of youtube.PlayerAPI.
+//
+// The double records every call, reports the values held in its Status and
+// lets tests emit player events:
+//
+//	p := youtubetest.NewPlayer()
+//	component := NewComponent(p) // depends on youtube.PlayerAPI
+//	p.Ready()
+//	p.Emit(youtube.StateChangeEvent{State: youtube.Playing})
+//	if p.Called("PauseVideo") != 1 { ... }
+//
+// Emitted events reach Subscribe, Events and the waits as they are, so the
+// double runs with plain go test. Only raw listeners added with
+// AddEventListener receive a *youtube.Event, built with youtube.NewEvent: they
+// need a JS environment, as the bindings do. Its Target is nil, so the
+// context of an ErrorEvent is lost to them.
+package youtubetest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/yttype"
+)
+
+// Call is a recorded method call on the double
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Status holds the values reported by the getters of the double. Setters and
+// playback controls update it the way the real player would.
+type Status struct {
+	State          youtube.PlayerState
+	CurrentTime    float64
+	Duration       float64
+	LoadedFraction float64
+	Volume         int
+	Muted          bool
+	PlaybackRate   float64
+	Rates          []float64
+	Quality        youtube.Quality
+	Qualities      []youtube.Quality
+	Loop           bool
+	Shuffle        bool
+	VideoID        string
+	VideoURL       string
+	EmbedCode      string
+	VideoData      *youtube.VideoData
+	Playlist       []string
+	PlaylistIndex  int
+	Iframe         youtube.JSValue
+}
+
+// Player is a test double of youtube.PlayerAPI. It is safe for concurrent use.
+type Player struct {
+	mu        sync.Mutex
+	status    Status
+	calls     []Call
+	listeners map[youtube.EventType][]*listener
+	changed   chan struct{} // closed and replaced whenever the status changes
+	hub       *youtube.Hub
+}
+
+var _ youtube.PlayerAPI = (*Player)(nil)
+
+// NewPlayer returns an unstarted double at full volume and normal rate
+func NewPlayer() *Player {
+	return &Player{
+		status: Status{
+			State:        youtube.Unstarted,
+			Volume:       100,
+			PlaybackRate: 1,
+			Rates:        []float64{1},
+		},
+		listeners: make(map[youtube.EventType][]*listener),
+		changed:   make(chan struct{}),
+		hub:       youtube.NewHub(),
+	}
+}
+
+// Status returns a copy of the current status
+func (p *Player) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
+
+// Update changes the status under the double's lock, waking up pending waits.
+// No event is emitted.
+func (p *Player) Update(fn func(s *Status)) {
+	p.mu.Lock()
+	fn(&p.status)
+	p.notifyLocked()
+	p.mu.Unlock()
+}
+
+// Calls returns every recorded call in order
+func (p *Player) Calls() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Call(nil), p.calls...)
+}
+
+// Called returns how many times the given method was called
+func (p *Player) Called(method string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, c := range p.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// ResetCalls forgets the recorded calls
+func (p *Player) ResetCalls() {
+	p.mu.Lock()
+	p.calls = nil
+	p.mu.Unlock()
+}
+
+// Ready marks the double as ready and emits an onReady event
+func (p *Player) Ready() {
+	p.hub.MarkReady()
+	p.Emit(youtube.ReadyEvent{})
+}
+
+// InjectError emits an onError event with the given code and the video,
+// playlist index and time of Status
+func (p *Player) InjectError(code youtube.Error) {
+	p.mu.Lock()
+	ev := youtube.ErrorEvent{
+		Err:           code,
+		VideoID:       p.status.VideoID,
+		PlaylistIndex: -1,
+		Time:          yttype.Seconds(p.status.CurrentTime),
+	}
+	if len(p.status.Playlist) > 0 {
+		ev.PlaylistIndex = p.status.PlaylistIndex
+	}
+	p.mu.Unlock()
+	p.Emit(ev)
+}
+
+// Emit delivers the event to the subscribers and event streams, then to the
+// raw listeners of its type. A StateChangeEvent also updates Status.State.
+func (p *Player) Emit(ev youtube.TypedEvent) {
+	p.mu.Lock()
+	if sc, ok := ev.(youtube.StateChangeEvent); ok {
+		p.status.State = sc.State
+	}
+	p.notifyLocked()
+	ls := append([]*listener(nil), p.listeners[ev.EventType()]...)
+	p.mu.Unlock()
+
+	p.hub.Emit(ev)
+
+	if len(ls) == 0 {
+		return
+	}
+	e := newEvent(ev)
+	for _, l := range ls {
+		l.fn(e)
+	}
+}
+
+func (p *Player) record(method string, args ...interface{}) {
+	p.calls = append(p.calls, Call{Method: method, Args: args})
+}
+
+func (p *Player) notifyLocked() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// do records a call and applies fn to the status under the lock
+func (p *Player) do(method string, fn func(s *Status), args ...interface{}) {
+	p.mu.Lock()
+	p.record(method, args...)
+	if fn != nil {
+		fn(&p.status)
+	}
+	p.notifyLocked()
+	p.mu.Unlock()
+}
+
+// get records a call and reads the status under the lock
+func (p *Player) get(method string) Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.record(method)
+	return p.status
+}
+
+// setState records a call, applies fn and emits a state change if the state
+// differs from the current one
+func (p *Player) setState(method string, state youtube.PlayerState, fn func(s *Status), args ...interface{}) {
+	p.mu.Lock()
+	p.record(method, args...)
+	if fn != nil {
+		fn(&p.status)
+	}
+	changed := p.status.State != state
+	p.notifyLocked()
+	p.mu.Unlock()
+	if changed {
+		p.Emit(youtube.StateChangeEvent{State: state})
+	}
+}
+
+func (p *Player) LoadVideoByID(vid string, startSec float64, q youtube.Quality) {
+	p.setState("LoadVideoByID", youtube.Playing, loadVideo(vid, startSec), vid, startSec, q)
+}
+
+func (p *Player) LoadVideoByID2(params *youtube.LoadByIDOptions) {
+	p.setState("LoadVideoByID2", youtube.Playing, loadVideo(params.VideoID, params.StartSeconds), params)
+}
+
+func (p *Player) CueVideoByID(vid string, startSec float64, q youtube.Quality) {
+	p.setState("CueVideoByID", youtube.VideoCued, loadVideo(vid, startSec), vid, startSec, q)
+}
+
+func (p *Player) CueVideoByID2(params *youtube.LoadByIDOptions) {
+	p.setState("CueVideoByID2", youtube.VideoCued, loadVideo(params.VideoID, params.StartSeconds), params)
+}
+
+func (p *Player) LoadVideoByURL(url string, startSec float64, q youtube.Quality) {
+	p.setState("LoadVideoByURL", youtube.Playing, loadVideo("", startSec), url, startSec, q)
+}
+
+func (p *Player) LoadVideoByURL2(params *youtube.LoadByURLOptions) {
+	p.setState("LoadVideoByURL2", youtube.Playing, loadVideo("", params.StartSeconds), params)
+}
+
+func (p *Player) CuePlaylist(ids []string, index int, startSec float64, q youtube.Quality) {
+	p.setState("CuePlaylist", youtube.VideoCued, loadPlaylist(ids, index, startSec), ids, index, startSec, q)
+}
+
+func (p *Player) CuePlaylist2(params *youtube.CuePlaylistOptions) {
+	p.setState("CuePlaylist2", youtube.VideoCued, loadPlaylist(nil, params.Index, params.StartSeconds), params)
+}
+
+func (p *Player) LoadPlaylist(ids []string, index int, startSec float64, q youtube.Quality) {
+	p.setState("LoadPlaylist", youtube.Playing, loadPlaylist(ids, index, startSec), ids, index, startSec, q)
+}
+
+func (p *Player) LoadPlaylist2(params *youtube.CuePlaylistOptions) {
+	p.setState("LoadPlaylist2", youtube.Playing, loadPlaylist(nil, params.Index, params.StartSeconds), params)
+}
+
+func loadVideo(vid string, startSec float64) func(s *Status) {
+	return func(s *Status) {
+		s.VideoID = vid
+		s.CurrentTime = startSec
+		s.Playlist = nil
+		s.PlaylistIndex = 0
+	}
+}
+
+func loadPlaylist(ids []string, index int, startSec float64) func(s *Status) {
+	return func(s *Status) {
+		s.Playlist = append([]string(nil), ids...)
+		s.PlaylistIndex = index
+		if index >= 0 && index < len(ids) {
+			s.VideoID = ids[index]
+		}
+		s.CurrentTime = startSec
+	}
+}
+
+func (p *Player) PlayVideo() {
+	p.setState("PlayVideo", youtube.Playing, nil)
+}
+
+func (p *Player) PauseVideo() {
+	p.setState("PauseVideo", youtube.Paused, nil)
+}
+
+func (p *Player) StopVideo() {
+	p.setState("StopVideo", youtube.Unstarted, func(s *Status) { s.CurrentTime = 0 })
+}
+
+func (p *Player) SeekTo(seconds float64, allowSeekAhead bool) {
+	p.do("SeekTo", func(s *Status) { s.CurrentTime = seconds }, seconds, allowSeekAhead)
+}
+
+func (p *Player) NextVideo() {
+	p.setState("NextVideo", youtube.Playing, playAt(func(i int) int { return i + 1 }))
+}
+
+func (p *Player) PreviousVideo() {
+	p.setState("PreviousVideo", youtube.Playing, playAt(func(i int) int { return i - 1 }))
+}
+
+func (p *Player) PlayVideoAt(index int) {
+	p.setState("PlayVideoAt", youtube.Playing, playAt(func(int) int { return index }), index)
+}
+
+func playAt(next func(int) int) func(s *Status) {
+	return func(s *Status) {
+		i := next(s.PlaylistIndex)
+		if i < 0 || i >= len(s.Playlist) {
+			return
+		}
+		s.PlaylistIndex = i
+		s.VideoID = s.Playlist[i]
+		s.CurrentTime = 0
+	}
+}
+
+func (p *Player) Mute() {
+	p.do("Mute", func(s *Status) { s.Muted = true })
+}
+
+func (p *Player) UnMute() {
+	p.do("UnMute", func(s *Status) { s.Muted = false })
+}
+
+func (p *Player) IsMuted() bool {
+	return p.get("IsMuted").Muted
+}
+
+func (p *Player) SetVolume(vol int) {
+	p.do("SetVolume", func(s *Status) { s.Volume = vol }, vol)
+}
+
+func (p *Player) Volume() int {
+	return p.get("Volume").Volume
+}
+
+func (p *Player) SetSize(width int, height int) youtube.JSValue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.record("SetSize", width, height)
+	return p.status.Iframe
+}
+
+func (p *Player) PlaybackRate() float64 {
+	return p.get("PlaybackRate").PlaybackRate
+}
+
+func (p *Player) SetPlaybackRate(suggestedRate float64) {
+	p.do("SetPlaybackRate", func(s *Status) { s.PlaybackRate = suggestedRate }, suggestedRate)
+	p.Emit(youtube.RateChangeEvent{Rate: suggestedRate})
+}
+
+func (p *Player) AvailablePlaybackRates() []float64 {
+	return p.get("AvailablePlaybackRates").Rates
+}
+
+func (p *Player) SetLoop(val bool) {
+	p.do("SetLoop", func(s *Status) { s.Loop = val }, val)
+}
+
+func (p *Player) SetShuffle(val bool) {
+	p.do("SetShuffle", func(s *Status) { s.Shuffle = val }, val)
+}
+
+func (p *Player) VideoLoadedFraction() float64 {
+	return p.get("VideoLoadedFraction").LoadedFraction
+}
+
+func (p *Player) PlayerState() youtube.PlayerState {
+	return p.get("PlayerState").State
+}
+
+func (p *Player) CurrentTime() float64 {
+	return p.get("CurrentTime").CurrentTime
+}
+
+func (p *Player) PlaybackQuality() youtube.Quality {
+	return p.get("PlaybackQuality").Quality
+}
+
+func (p *Player) SetPlaybackQuality(suggested youtube.Quality) {
+	p.do("SetPlaybackQuality", func(s *Status) { s.Quality = suggested }, suggested)
+	p.Emit(youtube.QualityChangeEvent{Quality: suggested})
+}
+
+func (p *Player) AvailableQualityLevels() []youtube.Quality {
+	return p.get("AvailableQualityLevels").Qualities
+}
+
+func (p *Player) Duration() float64 {
+	return p.get("Duration").Duration
+}
+
+func (p *Player) VideoURL() string {
+	return p.get("VideoURL").VideoURL
+}
+
+func (p *Player) VideoEmbedCode() string {
+	return p.get("VideoEmbedCode").EmbedCode
+}
+
+func (p *Player) VideoData() *youtube.VideoData {
+	return p.get("VideoData").VideoData
+}
+
+func (p *Player) Playlist() []string {
+	return p.get("Playlist").Playlist
+}
+
+func (p *Player) PlaylistIndex() int {
+	return p.get("PlaylistIndex").PlaylistIndex
+}
+
+func (p *Player) Iframe() youtube.JSValue {
+	return p.get("Iframe").Iframe
+}
+
+// Destroy marks the double as destroyed: pending waits return
+// youtube.ErrDestroyed, event streams are closed, listeners are released and
+// the OnDestroy hooks are called. Every call is recorded.
+func (p *Player) Destroy() {
+	p.do("Destroy", nil)
+	p.hub.Destroy(func() {
+		p.mu.Lock()
+		p.listeners = make(map[youtube.EventType][]*listener)
+		p.mu.Unlock()
+	})
+}
+
+// Lifecycle reports whether Ready or Destroy were called
+func (p *Player) Lifecycle() youtube.Lifecycle {
+	return p.hub.Lifecycle()
+}
+
+// OnDestroy registers fn to be called by Destroy, or calls it right away if
+// the double is already destroyed
+func (p *Player) OnDestroy(fn func()) (remove func()) {
+	return p.hub.OnDestroy(fn)
+}
+
+// Destroyed reports whether Destroy was called
+func (p *Player) Destroyed() bool {
+	return p.hub.Lifecycle() == youtube.LifecycleDestroyed
+}
+
+// WaitReady blocks until Ready is called
+func (p *Player) WaitReady(ctx context.Context) error {
+	return p.hub.WaitReady(ctx)
+}
+
+// WaitForState blocks until Status.State is the given state
+func (p *Player) WaitForState(ctx context.Context, state youtube.PlayerState) error {
+	return p.wait(ctx, func(s Status) bool { return s.State == state })
+}
+
+// WaitUntilTime blocks until Status.CurrentTime reaches t, or returns
+// youtube.ErrEnded if Status.State becomes Ended before
+func (p *Player) WaitUntilTime(ctx context.Context, t time.Duration) error {
+	var ended bool
+	err := p.wait(ctx, func(s Status) bool {
+		ended = s.CurrentTime < t.Seconds() && s.State == youtube.Ended
+		return s.CurrentTime >= t.Seconds() || ended
+	})
+	if err == nil && ended {
+		return youtube.ErrEnded
+	}
+	return err
+}
+
+// wait re-evaluates done on every status change until it reports true. An
+// injected error aborts the wait with a *youtube.PlayerError.
+func (p *Player) wait(ctx context.Context, done func(Status) bool) error {
+	if err := p.WaitReady(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := p.Events(ctx, youtube.OnError)
+	for {
+		p.mu.Lock()
+		ok, changed := done(p.status), p.changed
+		p.mu.Unlock()
+		if ok {
+			return nil
+		}
+		select {
+		case ev := <-errs:
+			if e, isErr := ev.(youtube.ErrorEvent); isErr {
+				return e.PlayerError()
+			}
+		case <-changed:
+		case <-p.hub.Done():
+			return youtube.ErrDestroyed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package youtubetest_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/youtubetest"
+)
+
+func TestInjectErrorAbortsWaitForState(t *testing.T) {
+	p := youtubetest.NewPlayer()
+	p.Ready()
+	p.LoadPlaylist([]string{"aaaaaaaaaaa", "bbbbbbbbbbb"}, 1, 0, youtube.Auto)
+	p.SeekTo(42, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() { errc <- p.WaitForState(ctx, youtube.Ended) }()
+	time.Sleep(10 * time.Millisecond)
+	p.InjectError(youtube.ErrNotForEmbedded)
+
+	var perr *youtube.PlayerError
+	if err := <-errc; !errors.As(err, &perr) {
+		t.Fatalf("WaitForState = %v, want a *PlayerError", err)
+	}
+	want := youtube.PlayerError{
+		Err:           youtube.ErrNotForEmbedded,
+		VideoID:       "bbbbbbbbbbb",
+		PlaylistIndex: 1,
+		Time:          42 * time.Second,
+	}
+	if *perr != want {
+		t.Errorf("error = %+v, want %+v", *perr, want)
+	}
+}
+
+func TestEventsDeliverEmittedEvents(t *testing.T) {
+	p := youtubetest.NewPlayer()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := p.Events(ctx, youtube.OnStateChange, youtube.OnError)
+
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 5, youtube.Auto)
+	p.SetVolume(10)
+	p.PauseVideo()
+	p.InjectError(youtube.ErrVideoNotFound)
+
+	want := []youtube.TypedEvent{
+		youtube.StateChangeEvent{State: youtube.Playing},
+		youtube.StateChangeEvent{State: youtube.Paused},
+		youtube.ErrorEvent{Err: youtube.ErrVideoNotFound, VideoID: "dQw4w9WgXcQ", PlaylistIndex: -1, Time: 5 * time.Second},
+	}
+	for i, w := range want {
+		select {
+		case ev := <-events:
+			if ev != w {
+				t.Errorf("event %d = %#v, want %#v", i, ev, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not delivered", i)
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestEventsDropTheOldest(t *testing.T) {
+	p := youtubetest.NewPlayer()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := p.Events(ctx, youtube.OnPlaybackRateChange)
+
+	n := youtube.EventStreamBuffer + 5
+	for i := 1; i <= n; i++ {
+		p.SetPlaybackRate(float64(i))
+	}
+	first := <-events
+	if want := (youtube.RateChangeEvent{Rate: 6}); first != want {
+		t.Errorf("oldest kept event = %#v, want %#v", first, want)
+	}
+	if got := len(events); got != youtube.EventStreamBuffer-1 {
+		t.Errorf("%d events left, want %d", got, youtube.EventStreamBuffer-1)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	p := youtubetest.NewPlayer()
+	var got []youtube.TypedEvent
+	unsubscribe := p.Subscribe(func(ev youtube.TypedEvent) { got = append(got, ev) }, youtube.OnPlaybackQualityChange)
+	p.SetPlaybackQuality(youtube.HD720)
+	p.PlayVideo()
+	unsubscribe()
+	p.SetPlaybackQuality(youtube.Small)
+
+	want := []youtube.TypedEvent{youtube.QualityChangeEvent{Quality: youtube.HD720}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %#v, want %#v", got, want)
+	}
+}
+
+func TestWaitUntilTime(t *testing.T) {
+	p := youtubetest.NewPlayer()
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() { errc <- p.WaitUntilTime(ctx, 10*time.Second) }()
+	p.Update(func(s *youtubetest.Status) { s.CurrentTime = 10 })
+	if err := <-errc; err != nil {
+		t.Errorf("WaitUntilTime = %v", err)
+	}
+
+	go func() { errc <- p.WaitUntilTime(ctx, time.Minute) }()
+	p.Emit(youtube.StateChangeEvent{State: youtube.Ended})
+	if err := <-errc; !errors.Is(err, youtube.ErrEnded) {
+		t.Errorf("WaitUntilTime past the end = %v, want ErrEnded", err)
+	}
+}
+
+func TestRecordsCalls(t *testing.T) {
+	p := youtubetest.NewPlayer()
+	p.CueVideoByID("dQw4w9WgXcQ", 30, youtube.HD720)
+	p.SeekTo(12, true)
+	p.SeekTo(20, false)
+	_ = p.CurrentTime()
+
+	if n := p.Called("SeekTo"); n != 2 {
+		t.Errorf("SeekTo called %d times, want 2", n)
+	}
+	want := []youtubetest.Call{
+		{Method: "CueVideoByID", Args: []interface{}{"dQw4w9WgXcQ", 30.0, youtube.HD720}},
+		{Method: "SeekTo", Args: []interface{}{12.0, true}},
+		{Method: "SeekTo", Args: []interface{}{20.0, false}},
+		{Method: "CurrentTime"},
+	}
+	if got := p.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %#v, want %#v", got, want)
+	}
+	if s := p.Status(); s.State != youtube.VideoCued || s.CurrentTime != 20 || s.VideoID != "dQw4w9WgXcQ" {
+		t.Errorf("status = %+v", s)
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	p := youtubetest.NewPlayer()
+	p.Ready()
+	events := p.Events(context.Background())
+	hooked := false
+	p.OnDestroy(func() { hooked = true })
+
+	errc := make(chan error, 1)
+	go func() { errc <- p.WaitForState(context.Background(), youtube.Playing) }()
+	p.Destroy()
+
+	if err := <-errc; !errors.Is(err, youtube.ErrDestroyed) {
+		t.Errorf("pending wait = %v, want ErrDestroyed", err)
+	}
+	for range events {
+	}
+	if !hooked || p.Lifecycle() != youtube.LifecycleDestroyed {
+		t.Errorf("hook called: %v, lifecycle %v", hooked, p.Lifecycle())
+	}
+}
@@ -0,0 +1,299 @@
+// Package ytembed renders Youtube embeds on the server. It builds the
+// https://www.youtube.com/embed/... URL of a video or playlist from typed
+// player parameters, documented at
+// https://developers.google.com/youtube/player_parameters, and the matching
+// <iframe> snippet, without the client side Iframe API.
+//
+//	e := ytembed.Embed{
+//		VideoID: "dQw4w9WgXcQ",
+//		Params:  ytembed.Params{Start: 90 * time.Second, Loop: true},
+//	}
+//	src, err := e.URL()
+//	// https://www.youtube.com/embed/dQw4w9WgXcQ?loop=1&playlist=dQw4w9WgXcQ&start=90
+//
+// FuncMap provides the same for html/template.
+package ytembed
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iocat/youtube/yttype"
+	"github.com/iocat/youtube/yturl"
+)
+
+const (
+	host         = "www.youtube.com"
+	noCookieHost = "www.youtube-nocookie.com"
+
+	// DefaultWidth and DefaultHeight are the iframe size used when an Embed
+	// sets none
+	DefaultWidth  = 640
+	DefaultHeight = 360
+	// DefaultTitle is the iframe title used when an Embed sets none
+	DefaultTitle = "YouTube video player"
+)
+
+// Errors returned when building an embed
+var (
+	ErrNoContent     = errors.New("ytembed: embed has neither a video nor a playlist")
+	ErrInvalidRange  = errors.New("ytembed: end is not after start")
+	ErrInvalidOrigin = errors.New("ytembed: origin must be a scheme and host, e.g. https://example.com")
+	ErrInvalidColor  = errors.New("ytembed: color must be red or white")
+)
+
+// Params are the typed player parameters. The zero value of every field
+// leaves the player's default.
+type Params struct {
+	Autoplay bool
+	// Mute starts the player muted, which browsers require for autoplay
+	Mute bool
+	// ShowCaptions shows closed captions by default (cc_load_policy=1)
+	ShowCaptions bool
+	// CaptionsLanguage is the ISO 639-1 language of the default captions
+	CaptionsLanguage string
+	// Color of the progress bar, red or white
+	Color string
+	// HideControls hides the player controls (controls=0)
+	HideControls bool
+	// DisableKeyboard ignores the keyboard controls (disablekb=1)
+	DisableKeyboard bool
+	// EnableJSAPI allows controlling the player through the Iframe API
+	EnableJSAPI bool
+	// Start and End bound the playback. The player takes whole seconds:
+	// Start is rounded down and End up, so the range is never shortened.
+	Start time.Duration
+	End   time.Duration
+	// HideFullscreenButton removes the fullscreen button (fs=0)
+	HideFullscreenButton bool
+	// Language is the ISO 639-1 language of the player interface (hl)
+	Language string
+	// HideAnnotations hides video annotations (iv_load_policy=3)
+	HideAnnotations bool
+	// Loop plays the video or playlist again and again. Looping a single
+	// video sets the playlist parameter to the video, as the player requires.
+	Loop bool
+	// Origin is the scheme and host of the embedding page. It protects the
+	// Iframe API and should be set whenever EnableJSAPI is.
+	Origin string
+	// Playlist are videos played after the embedded one
+	Playlist []string
+	// PlaysInline plays inline on iOS instead of fullscreen
+	PlaysInline bool
+	// RelatedFromSameChannel limits the related videos shown at the end to
+	// the channel of the video (rel=0)
+	RelatedFromSameChannel bool
+	// WidgetReferrer is the URL where the player is embedded, for analytics
+	WidgetReferrer string
+}
+
+// Embed is a video or playlist to embed
+type Embed struct {
+	// VideoID is the video to embed
+	VideoID string
+	// List is the playlist to embed. With ListType search or user_uploads it
+	// is the search query or the user name.
+	List     string
+	ListType yttype.ListType
+	Params   Params
+	// NoCookie embeds from youtube-nocookie.com, the privacy-enhanced mode
+	NoCookie bool
+
+	// Width, Height and Title are the iframe attributes. Zero values use
+	// DefaultWidth, DefaultHeight and DefaultTitle.
+	Width  int
+	Height int
+	Title  string
+}
+
+// FromRef returns an embed of the video or playlist of a parsed link
+func FromRef(ref *yturl.Ref) Embed {
+	e := Embed{
+		VideoID: ref.VideoID,
+		List:    ref.PlaylistID,
+		Params:  Params{Start: ref.Start, End: ref.End},
+	}
+	if ref.ListType != yttype.ListTypePlaylist {
+		e.ListType = ref.ListType
+	}
+	return e
+}
+
+// URL returns the embed URL
+func (e Embed) URL() (string, error) {
+	if e.VideoID == "" && e.List == "" {
+		return "", ErrNoContent
+	}
+	path := "/embed"
+	switch {
+	case e.VideoID != "":
+		if !yturl.ValidVideoID(e.VideoID) {
+			return "", fmt.Errorf("ytembed: invalid video ID %q", e.VideoID)
+		}
+		path += "/" + e.VideoID
+	case e.ListType == "" || e.ListType == yttype.ListTypePlaylist:
+		path += "/videoseries"
+	}
+	q, err := e.query()
+	if err != nil {
+		return "", err
+	}
+	u := url.URL{Scheme: "https", Host: host, Path: path, RawQuery: q.Encode()}
+	if e.NoCookie {
+		u.Host = noCookieHost
+	}
+	return u.String(), nil
+}
+
+func (e Embed) query() (url.Values, error) {
+	p := e.Params
+	q := url.Values{}
+	setBool := func(key string, cond bool, value string) {
+		if cond {
+			q.Set(key, value)
+		}
+	}
+	setBool("autoplay", p.Autoplay, "1")
+	setBool("mute", p.Mute, "1")
+	setBool("cc_load_policy", p.ShowCaptions, "1")
+	setBool("controls", p.HideControls, "0")
+	setBool("disablekb", p.DisableKeyboard, "1")
+	setBool("enablejsapi", p.EnableJSAPI, "1")
+	setBool("fs", p.HideFullscreenButton, "0")
+	setBool("iv_load_policy", p.HideAnnotations, "3")
+	setBool("loop", p.Loop, "1")
+	setBool("playsinline", p.PlaysInline, "1")
+	setBool("rel", p.RelatedFromSameChannel, "0")
+	if p.CaptionsLanguage != "" {
+		q.Set("cc_lang_pref", p.CaptionsLanguage)
+	}
+	if p.Language != "" {
+		q.Set("hl", p.Language)
+	}
+	if p.WidgetReferrer != "" {
+		q.Set("widget_referrer", p.WidgetReferrer)
+	}
+
+	switch p.Color {
+	case "":
+	case "red", "white":
+		q.Set("color", p.Color)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidColor, p.Color)
+	}
+
+	start, end := yttype.RangeSeconds(p.Start, p.End)
+	if start > 0 {
+		q.Set("start", strconv.Itoa(start))
+	}
+	if p.End > 0 {
+		if p.End <= p.Start {
+			return nil, fmt.Errorf("%w: start %v, end %v", ErrInvalidRange, p.Start, p.End)
+		}
+		q.Set("end", strconv.Itoa(end))
+	}
+
+	if p.Origin != "" {
+		origin, ok := yturl.NormalizeOrigin(p.Origin)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidOrigin, p.Origin)
+		}
+		q.Set("origin", origin)
+	}
+
+	playlist := p.Playlist
+	if p.Loop && len(playlist) == 0 && e.VideoID != "" && e.List == "" {
+		// a single video only loops as a playlist of itself
+		playlist = []string{e.VideoID}
+	}
+	for _, id := range playlist {
+		if !yturl.ValidVideoID(id) {
+			return nil, fmt.Errorf("ytembed: invalid video ID %q in playlist", id)
+		}
+	}
+	if len(playlist) > 0 {
+		q.Set("playlist", strings.Join(playlist, ","))
+	}
+
+	if e.List != "" {
+		switch e.ListType {
+		case "", yttype.ListTypePlaylist:
+			if !yturl.ValidPlaylistID(e.List) {
+				return nil, fmt.Errorf("ytembed: invalid playlist ID %q", e.List)
+			}
+		case yttype.ListTypeSearch, yttype.ListTypeUserUploads:
+			q.Set("listType", string(e.ListType))
+		default:
+			return nil, fmt.Errorf("ytembed: unknown list type %q", e.ListType)
+		}
+		q.Set("list", e.List)
+	}
+	return q, nil
+}
+
+var iframeTemplate = template.Must(template.New("iframe").Parse(
+	`<iframe width="{{.Width}}" height="{{.Height}}" src="{{.Src}}" title="{{.Title}}" frameborder="0" ` +
+		`allow="accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture; web-share" ` +
+		`referrerpolicy="strict-origin-when-cross-origin" allowfullscreen></iframe>`))
+
+// HTML returns the <iframe> snippet of the embed. Every attribute is escaped.
+func (e Embed) HTML() (template.HTML, error) {
+	src, err := e.URL()
+	if err != nil {
+		return "", err
+	}
+	data := struct {
+		Width, Height int
+		Src           template.URL
+		Title         string
+	}{e.Width, e.Height, template.URL(src), e.Title}
+	if data.Width <= 0 {
+		data.Width = DefaultWidth
+	}
+	if data.Height <= 0 {
+		data.Height = DefaultHeight
+	}
+	if data.Title == "" {
+		data.Title = DefaultTitle
+	}
+	var buf bytes.Buffer
+	if err := iframeTemplate.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// FuncMap returns html/template functions rendering embeds:
+//
+//	youtubeEmbedURL  Embed -> the embed URL, for a src attribute
+//	youtubeIframe    Embed -> the <iframe> snippet
+//	youtubeVideo     string -> the <iframe> snippet of a video ID or link
+//
+// For example:
+//
+//	{{youtubeVideo .Link}}
+//	<iframe src="{{youtubeEmbedURL .Embed}}"></iframe>
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"youtubeEmbedURL": func(e Embed) (template.URL, error) {
+			src, err := e.URL()
+			return template.URL(src), err
+		},
+		"youtubeIframe": func(e Embed) (template.HTML, error) {
+			return e.HTML()
+		},
+		"youtubeVideo": func(link string) (template.HTML, error) {
+			ref, err := yturl.Parse(link)
+			if err != nil {
+				return "", err
+			}
+			return FromRef(ref).HTML()
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package ytembed_test
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube/ytembed"
+	"github.com/iocat/youtube/yttype"
+	"github.com/iocat/youtube/yturl"
+)
+
+const (
+	id   = "dQw4w9WgXcQ"
+	id2  = "9bZkp7q19f0"
+	list = "PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI"
+)
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		name string
+		e    ytembed.Embed
+		want string
+	}{
+		{"video", ytembed.Embed{VideoID: id}, "https://www.youtube.com/embed/" + id + "?"},
+		{"no cookie", ytembed.Embed{VideoID: id, NoCookie: true}, "https://www.youtube-nocookie.com/embed/" + id + "?"},
+		{"playlist", ytembed.Embed{List: list}, "https://www.youtube.com/embed/videoseries?list=" + list},
+		{"video of a playlist", ytembed.Embed{VideoID: id, List: list}, "https://www.youtube.com/embed/" + id + "?list=" + list},
+		{"search", ytembed.Embed{List: "cats & dogs", ListType: yttype.ListTypeSearch},
+			"https://www.youtube.com/embed?list=cats+%26+dogs&listType=search"},
+		{"flags", ytembed.Embed{VideoID: id, Params: ytembed.Params{
+			Autoplay: true, Mute: true, ShowCaptions: true, HideControls: true, DisableKeyboard: true,
+			EnableJSAPI: true, HideFullscreenButton: true, HideAnnotations: true, PlaysInline: true,
+			RelatedFromSameChannel: true,
+		}}, "https://www.youtube.com/embed/" + id + "?autoplay=1&cc_load_policy=1&controls=0&disablekb=1" +
+			"&enablejsapi=1&fs=0&iv_load_policy=3&mute=1&playsinline=1&rel=0"},
+		{"strings", ytembed.Embed{VideoID: id, Params: ytembed.Params{
+			CaptionsLanguage: "pt-BR", Language: "fr", Color: "white", WidgetReferrer: "https://example.com/a?b=c",
+		}}, "https://www.youtube.com/embed/" + id + "?cc_lang_pref=pt-BR&color=white&hl=fr" +
+			"&widget_referrer=https%3A%2F%2Fexample.com%2Fa%3Fb%3Dc"},
+		{"range", ytembed.Embed{VideoID: id, Params: ytembed.Params{Start: 90 * time.Second, End: 120 * time.Second}},
+			"https://www.youtube.com/embed/" + id + "?end=120&start=90"},
+		{"sub-second range", ytembed.Embed{VideoID: id, Params: ytembed.Params{Start: 1200 * time.Millisecond, End: 1800 * time.Millisecond}},
+			"https://www.youtube.com/embed/" + id + "?end=2&start=1"},
+		{"end rounded up", ytembed.Embed{VideoID: id, Params: ytembed.Params{End: 90500 * time.Millisecond}},
+			"https://www.youtube.com/embed/" + id + "?end=91"},
+		{"loop a video", ytembed.Embed{VideoID: id, Params: ytembed.Params{Loop: true}},
+			"https://www.youtube.com/embed/" + id + "?loop=1&playlist=" + id},
+		{"loop a playlist parameter", ytembed.Embed{VideoID: id, Params: ytembed.Params{Loop: true, Playlist: []string{id2, id}}},
+			"https://www.youtube.com/embed/" + id + "?loop=1&playlist=" + id2 + "%2C" + id},
+		{"loop a list", ytembed.Embed{List: list, Params: ytembed.Params{Loop: true}},
+			"https://www.youtube.com/embed/videoseries?list=" + list + "&loop=1"},
+		{"origin", ytembed.Embed{VideoID: id, Params: ytembed.Params{EnableJSAPI: true, Origin: "https://example.com/"}},
+			"https://www.youtube.com/embed/" + id + "?enablejsapi=1&origin=https%3A%2F%2Fexample.com"},
+	}
+	for _, tt := range tests {
+		got, err := tt.e.URL()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != strings.TrimSuffix(tt.want, "?") {
+			t.Errorf("%s: URL() =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestURLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		e    ytembed.Embed
+		err  error
+	}{
+		{"empty", ytembed.Embed{}, ytembed.ErrNoContent},
+		{"end before start", ytembed.Embed{VideoID: id, Params: ytembed.Params{Start: 20 * time.Second, End: 10 * time.Second}}, ytembed.ErrInvalidRange},
+		{"empty range", ytembed.Embed{VideoID: id, Params: ytembed.Params{Start: 1500 * time.Millisecond, End: 1500 * time.Millisecond}}, ytembed.ErrInvalidRange},
+		{"origin with a path", ytembed.Embed{VideoID: id, Params: ytembed.Params{Origin: "https://example.com/page"}}, ytembed.ErrInvalidOrigin},
+		{"origin without scheme", ytembed.Embed{VideoID: id, Params: ytembed.Params{Origin: "example.com"}}, ytembed.ErrInvalidOrigin},
+		{"color", ytembed.Embed{VideoID: id, Params: ytembed.Params{Color: "blue"}}, ytembed.ErrInvalidColor},
+	}
+	for _, tt := range tests {
+		if got, err := tt.e.URL(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: URL() = %q, %v, want %v", tt.name, got, err, tt.err)
+		}
+	}
+
+	invalid := []ytembed.Embed{
+		{VideoID: "dQw4w9WgXc"},
+		{VideoID: id, Params: ytembed.Params{Playlist: []string{"nope"}}},
+		{List: "P!"},
+		{List: "x", ListType: "channel"},
+	}
+	for _, e := range invalid {
+		if got, err := e.URL(); err == nil {
+			t.Errorf("URL() of %+v = %q, want an error", e, got)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	got, err := ytembed.Embed{VideoID: id, Params: ytembed.Params{Start: 90 * time.Second, Mute: true}}.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`width="640" height="360"`,
+		`src="https://www.youtube.com/embed/` + id + `?mute=1&amp;start=90"`,
+		`title="YouTube video player"`,
+		`allowfullscreen></iframe>`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("HTML() = %s\nmissing %s", got, want)
+		}
+	}
+
+	got, err = ytembed.Embed{VideoID: id, Width: 320, Height: 180, Title: `"><script>alert(1)</script>`}.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(got), "<script>") || !strings.Contains(string(got), `title="&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;"`) {
+		t.Errorf("title not escaped: %s", got)
+	}
+	if !strings.Contains(string(got), `width="320" height="180"`) {
+		t.Errorf("size not used: %s", got)
+	}
+}
+
+func TestFromRef(t *testing.T) {
+	ref, err := yturl.Parse("https://youtu.be/" + id + "?t=1m30s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ytembed.FromRef(ref).URL()
+	if want := "https://www.youtube.com/embed/" + id + "?start=90"; err != nil || got != want {
+		t.Errorf("URL() = %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestFuncMap(t *testing.T) {
+	tmpl := template.Must(template.New("").Funcs(ytembed.FuncMap()).Parse(
+		`<a href="{{youtubeEmbedURL .}}"></a>{{youtubeIframe .}}{{youtubeVideo "https://youtu.be/` + id + `"}}`))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ytembed.Embed{VideoID: id2}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<a href="https://www.youtube.com/embed/` + id2 + `"></a>`,
+		`<iframe width="640" height="360" src="https://www.youtube.com/embed/` + id2 + `"`,
+		`src="https://www.youtube.com/embed/` + id + `"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("template output %s\nmissing %s", out, want)
+		}
+	}
+
+	if err := tmpl.Execute(&buf, ytembed.Embed{}); err == nil {
+		t.Error("executing with an empty embed succeeded")
+	}
+}
@@ -0,0 +1,103 @@
+//go:build !wasm
+
+// Package ytfake installs a scriptable, in-memory fake of the Youtube Iframe
+// API's YT.Player on js.Global so the bindings can be exercised offline, for
+// instance with gopherjs test under Node.
+//
+//	fake := ytfake.Install()
+//	defer fake.Uninstall()
+//
+//	p := youtube.NewPlayer("player", youtube.NewProperties())
+//	fp := fake.Last()
+//	fp.Ready()
+//	p.PlayVideo()
+//	fp.Advance(10)
+//	if fp.Called("playVideo") != 1 { ... }
+//
+// The fake follows the state transitions of the real player on play, pause,
+// seek and cue, fires the callbacks given in PlayerEvents as well as those
+// added with addEventListener, and lets tests inject errors. It is not safe
+// for concurrent use outside of the single JS thread.
+package ytfake
+
+import (
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/iocat/youtube"
+)
+
+// DefaultDuration is the duration in seconds of every video unless set in
+// Fake.Durations
+const DefaultDuration = 100
+
+// Fake is an installed fake of the YT namespace
+type Fake struct {
+	// Durations maps a video ID to its duration in seconds
+	Durations map[string]float64
+	// Qualities are the quality levels reported for every video
+	Qualities []youtube.Quality
+	// Rates are the playback rates reported for every video
+	Rates []float64
+	// AutoReady fires onReady asynchronously after each player is created,
+	// as the real API does. Otherwise tests call Player.Ready themselves.
+	AutoReady bool
+
+	prev    *js.Object
+	players []*Player
+}
+
+// Install replaces the global YT namespace with a fake one and returns it
+func Install() *Fake {
+	f := &Fake{
+		Durations: make(map[string]float64),
+		Qualities: []youtube.Quality{youtube.HD720, youtube.Large, youtube.Medium, youtube.Small},
+		Rates:     []float64{0.25, 0.5, 1, 1.5, 2},
+		prev:      js.Global.Get("YT"),
+	}
+
+	states := js.Global.Get("Object").New()
+	states.Set("UNSTARTED", int(youtube.Unstarted))
+	states.Set("ENDED", int(youtube.Ended))
+	states.Set("PLAYING", int(youtube.Playing))
+	states.Set("PAUSED", int(youtube.Paused))
+	states.Set("BUFFERING", int(youtube.Buffering))
+	states.Set("CUED", int(youtube.VideoCued))
+
+	yt := js.Global.Get("Object").New()
+	yt.Set("loaded", 1)
+	yt.Set("PlayerState", states)
+	yt.Set("Player", js.MakeFunc(func(this *js.Object, args []*js.Object) interface{} {
+		p := newPlayer(f, this, args)
+		f.players = append(f.players, p)
+		if f.AutoReady {
+			js.Global.Call("setTimeout", p.Ready, 0)
+		}
+		return nil
+	}))
+	js.Global.Set("YT", yt)
+	return f
+}
+
+// Uninstall restores the YT namespace that was present before Install
+func (f *Fake) Uninstall() {
+	js.Global.Set("YT", f.prev)
+}
+
+// Players returns every player created since Install, in creation order
+func (f *Fake) Players() []*Player {
+	return append([]*Player(nil), f.players...)
+}
+
+// Last returns the most recently created player, or nil if there is none
+func (f *Fake) Last() *Player {
+	if len(f.players) == 0 {
+		return nil
+	}
+	return f.players[len(f.players)-1]
+}
+
+func (f *Fake) duration(videoID string) float64 {
+	if d, ok := f.Durations[videoID]; ok {
+		return d
+	}
+	return DefaultDuration
+}
@@ -0,0 +1,557 @@
+//go:build !wasm
+
+package ytfake
+
+import (
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/iocat/youtube"
+)
+
+// Call is a recorded method call on a fake player
+type Call struct {
+	Method string
+	// Args are the arguments converted with (*js.Object).Interface
+	Args []interface{}
+}
+
+// Player is the fake of a single YT.Player instance
+type Player struct {
+	// ElementID is the id of the element the player was created for
+	ElementID string
+
+	fake      *Fake
+	obj       *js.Object
+	events    *js.Object
+	listeners map[string][]*js.Object
+	calls     []Call
+	destroyed bool
+	removed   bool // the iframe left the document
+
+	state       youtube.PlayerState
+	videoID     string
+	playlist    []string
+	index       int
+	current     float64
+	end         float64
+	volume      int
+	muted       bool
+	rate        float64
+	quality     youtube.Quality
+	loop        bool
+	loaded      float64
+	methodNames []string
+}
+
+func newPlayer(f *Fake, this *js.Object, args []*js.Object) *Player {
+	p := &Player{
+		fake:      f,
+		obj:       this,
+		listeners: make(map[string][]*js.Object),
+		state:     youtube.Unstarted,
+		volume:    100,
+		rate:      1,
+		quality:   youtube.Medium,
+	}
+	if len(args) > 0 {
+		p.ElementID = args[0].String()
+	}
+	if len(args) > 1 && defined(args[1]) {
+		props := args[1]
+		p.events = props.Get("events")
+		if id := props.Get("videoId"); defined(id) {
+			p.videoID = id.String()
+			p.state = youtube.VideoCued
+		}
+	}
+	p.install()
+	return p
+}
+
+// JS returns the fake JS player object
+func (p *Player) JS() *js.Object {
+	return p.obj
+}
+
+// Target returns the bindings' view of the fake player, as found in
+// youtube.Event.Target
+func (p *Player) Target() *youtube.Player {
+	return &youtube.Player{Object: p.obj}
+}
+
+// Calls returns every recorded method call in order
+func (p *Player) Calls() []Call {
+	return append([]Call(nil), p.calls...)
+}
+
+// Called returns how many times the given JS method was called
+func (p *Player) Called(method string) int {
+	n := 0
+	for _, c := range p.calls {
+		if c.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// LastCall returns the last recorded call of the given JS method
+func (p *Player) LastCall(method string) (Call, bool) {
+	for i := len(p.calls) - 1; i >= 0; i-- {
+		if p.calls[i].Method == method {
+			return p.calls[i], true
+		}
+	}
+	return Call{}, false
+}
+
+// ResetCalls forgets the recorded calls
+func (p *Player) ResetCalls() {
+	p.calls = nil
+}
+
+// State returns the current state of the fake player
+func (p *Player) State() youtube.PlayerState {
+	return p.state
+}
+
+// Destroyed reports whether destroy was called on the player
+func (p *Player) Destroyed() bool {
+	return p.destroyed
+}
+
+// RemoveIframe removes the iframe of the player from the document without
+// destroying the player, as a page dropping the player would
+func (p *Player) RemoveIframe() {
+	p.removed = true
+}
+
+// RestoreIframe puts the iframe removed by RemoveIframe back in the document,
+// as a page moving the player would
+func (p *Player) RestoreIframe() {
+	p.removed = false
+}
+
+// Ready fires the onReady event
+func (p *Player) Ready() {
+	p.fire(youtube.OnReady, nil)
+}
+
+// Fire fires an arbitrary event with the given data
+func (p *Player) Fire(event youtube.EventType, data interface{}) {
+	p.fire(event, data)
+}
+
+// InjectError fires an onError event with the given error code
+func (p *Player) InjectError(code youtube.Error) {
+	p.fire(youtube.OnError, int(code))
+}
+
+// SetState moves the player to the given state, firing onStateChange
+func (p *Player) SetState(s youtube.PlayerState) {
+	p.setState(s)
+}
+
+// SetLoadedFraction sets the value reported by getVideoLoadedFraction
+func (p *Player) SetLoadedFraction(f float64) {
+	p.loaded = f
+}
+
+// Advance moves the playback time forward by the given number of seconds
+// scaled by the playback rate, if the player is playing. Reaching the end of
+// the video moves the player to the next playlist entry if looping, or to the
+// Ended state.
+func (p *Player) Advance(seconds float64) {
+	if p.state != youtube.Playing {
+		return
+	}
+	p.current += seconds * p.rate
+	if p.current < p.duration() {
+		return
+	}
+	p.current = p.duration()
+	switch {
+	case len(p.playlist) > 0 && p.index+1 < len(p.playlist):
+		p.playAt(p.index + 1)
+	case len(p.playlist) > 0 && p.loop:
+		p.playAt(0)
+	default:
+		p.setState(youtube.Ended)
+	}
+}
+
+func (p *Player) duration() float64 {
+	if p.videoID == "" {
+		return 0
+	}
+	if p.end > 0 {
+		return p.end
+	}
+	return p.fake.duration(p.videoID)
+}
+
+func (p *Player) setState(s youtube.PlayerState) {
+	if p.state == s {
+		return
+	}
+	p.state = s
+	p.fire(youtube.OnStateChange, int(s))
+}
+
+// play moves through buffering to playing
+func (p *Player) play() {
+	if p.state == youtube.Playing {
+		return
+	}
+	p.setState(youtube.Buffering)
+	p.setState(youtube.Playing)
+}
+
+func (p *Player) load(videoID string, start, end float64, autoplay bool) {
+	p.videoID = videoID
+	p.current = start
+	p.end = end
+	p.loaded = 0
+	if autoplay {
+		p.setState(youtube.Unstarted)
+		p.play()
+		return
+	}
+	p.setState(youtube.VideoCued)
+}
+
+func (p *Player) playAt(index int) {
+	if index < 0 || index >= len(p.playlist) {
+		return
+	}
+	p.index = index
+	p.load(p.playlist[index], 0, 0, true)
+}
+
+func (p *Player) fire(event youtube.EventType, data interface{}) {
+	e := js.Global.Get("Object").New()
+	e.Set("target", p.obj)
+	e.Set("data", data)
+	if defined(p.events) {
+		if cb := p.events.Get(string(event)); defined(cb) {
+			cb.Invoke(e)
+		}
+	}
+	for _, l := range append([]*js.Object(nil), p.listeners[string(event)]...) {
+		if l.Get("call") == js.Undefined {
+			// a listener given by the name of a global function
+			l = js.Global.Get(l.String())
+		}
+		l.Invoke(e)
+	}
+}
+
+func (p *Player) method(name string, fn func(args []*js.Object) interface{}) {
+	p.methodNames = append(p.methodNames, name)
+	p.obj.Set(name, js.MakeFunc(func(this *js.Object, args []*js.Object) interface{} {
+		c := Call{Method: name, Args: make([]interface{}, len(args))}
+		for i, a := range args {
+			c.Args[i] = a.Interface()
+		}
+		p.calls = append(p.calls, c)
+		return fn(args)
+	}))
+}
+
+func (p *Player) install() {
+	// Queueing functions
+	p.method("loadVideoById", func(args []*js.Object) interface{} {
+		id, start, end := videoArgs(args, "videoId")
+		p.playlist = nil
+		p.load(id, start, end, true)
+		return nil
+	})
+	p.method("cueVideoById", func(args []*js.Object) interface{} {
+		id, start, end := videoArgs(args, "videoId")
+		p.playlist = nil
+		p.load(id, start, end, false)
+		return nil
+	})
+	p.method("loadVideoByUrl", func(args []*js.Object) interface{} {
+		url, start, end := videoArgs(args, "mediaContentUrl")
+		p.playlist = nil
+		p.load(url, start, end, true)
+		return nil
+	})
+	p.method("cueVideoByUrl", func(args []*js.Object) interface{} {
+		url, start, end := videoArgs(args, "mediaContentUrl")
+		p.playlist = nil
+		p.load(url, start, end, false)
+		return nil
+	})
+	p.method("cuePlaylist", func(args []*js.Object) interface{} {
+		p.queuePlaylist(args, false)
+		return nil
+	})
+	p.method("loadPlaylist", func(args []*js.Object) interface{} {
+		p.queuePlaylist(args, true)
+		return nil
+	})
+
+	// Playback controls and player settings
+	p.method("playVideo", func([]*js.Object) interface{} {
+		p.play()
+		return nil
+	})
+	p.method("pauseVideo", func([]*js.Object) interface{} {
+		p.setState(youtube.Paused)
+		return nil
+	})
+	p.method("stopVideo", func([]*js.Object) interface{} {
+		p.current = 0
+		p.setState(youtube.Unstarted)
+		return nil
+	})
+	p.method("seekTo", func(args []*js.Object) interface{} {
+		p.current = argFloat(args, 0)
+		if d := p.duration(); p.current >= d {
+			p.current = d
+			p.setState(youtube.Ended)
+			return nil
+		}
+		if p.state == youtube.Paused {
+			return nil
+		}
+		if p.state == youtube.Playing {
+			p.setState(youtube.Buffering)
+		}
+		p.play()
+		return nil
+	})
+	p.method("nextVideo", func([]*js.Object) interface{} {
+		next := p.index + 1
+		if next >= len(p.playlist) && p.loop {
+			next = 0
+		}
+		p.playAt(next)
+		return nil
+	})
+	p.method("previousVideo", func([]*js.Object) interface{} {
+		prev := p.index - 1
+		if prev < 0 && p.loop {
+			prev = len(p.playlist) - 1
+		}
+		p.playAt(prev)
+		return nil
+	})
+	p.method("playVideoAt", func(args []*js.Object) interface{} {
+		p.playAt(argInt(args, 0))
+		return nil
+	})
+	p.method("mute", func([]*js.Object) interface{} {
+		p.muted = true
+		return nil
+	})
+	p.method("unMute", func([]*js.Object) interface{} {
+		p.muted = false
+		return nil
+	})
+	p.method("isMuted", func([]*js.Object) interface{} {
+		return p.muted
+	})
+	p.method("setVolume", func(args []*js.Object) interface{} {
+		p.volume = argInt(args, 0)
+		return nil
+	})
+	p.method("getVolume", func([]*js.Object) interface{} {
+		return p.volume
+	})
+	p.method("setSize", func(args []*js.Object) interface{} {
+		return p.obj
+	})
+	p.method("getPlaybackRate", func([]*js.Object) interface{} {
+		return p.rate
+	})
+	p.method("setPlaybackRate", func(args []*js.Object) interface{} {
+		p.rate = argFloat(args, 0)
+		p.fire(youtube.OnPlaybackRateChange, p.rate)
+		return nil
+	})
+	p.method("getAvailablePlaybackRates", func([]*js.Object) interface{} {
+		return p.fake.Rates
+	})
+	p.method("setLoop", func(args []*js.Object) interface{} {
+		p.loop = len(args) > 0 && args[0].Bool()
+		return nil
+	})
+	p.method("setShuffle", func(args []*js.Object) interface{} {
+		return nil
+	})
+
+	// Playback status
+	p.method("getVideoLoadedFraction", func([]*js.Object) interface{} {
+		return p.loaded
+	})
+	p.method("getPlayerState", func([]*js.Object) interface{} {
+		return int(p.state)
+	})
+	p.method("getCurrentTime", func([]*js.Object) interface{} {
+		return p.current
+	})
+	p.method("getPlaybackQuality", func([]*js.Object) interface{} {
+		return string(p.quality)
+	})
+	p.method("setPlaybackQuality", func(args []*js.Object) interface{} {
+		if len(args) > 0 {
+			p.quality = youtube.Quality(args[0].String())
+		}
+		p.fire(youtube.OnPlaybackQualityChange, string(p.quality))
+		return nil
+	})
+	p.method("getAvailableQualityLevels", func([]*js.Object) interface{} {
+		levels := make([]string, len(p.fake.Qualities))
+		for i, q := range p.fake.Qualities {
+			levels[i] = string(q)
+		}
+		return levels
+	})
+
+	// Video information
+	p.method("getDuration", func([]*js.Object) interface{} {
+		return p.duration()
+	})
+	p.method("getVideoUrl", func([]*js.Object) interface{} {
+		return "https://www.youtube.com/watch?v=" + p.videoID
+	})
+	p.method("getVideoEmbedCode", func([]*js.Object) interface{} {
+		return `<iframe src="https://www.youtube.com/embed/` + p.videoID + `"></iframe>`
+	})
+	p.method("getVideoData", func([]*js.Object) interface{} {
+		data := js.Global.Get("Object").New()
+		data.Set("video_id", p.videoID)
+		data.Set("author", "")
+		data.Set("title", "")
+		data.Set("video_quality", string(p.quality))
+		return data
+	})
+
+	// Playlist information
+	p.method("getPlaylist", func([]*js.Object) interface{} {
+		if len(p.playlist) == 0 {
+			return nil
+		}
+		return p.playlist
+	})
+	p.method("getPlaylistIndex", func([]*js.Object) interface{} {
+		return p.index
+	})
+
+	// Event listeners, DOM nodes and destruction
+	p.method("addEventListener", func(args []*js.Object) interface{} {
+		if len(args) < 2 {
+			return nil
+		}
+		name := args[0].String()
+		p.listeners[name] = append(p.listeners[name], args[1])
+		return nil
+	})
+	p.method("removeEventListener", func(args []*js.Object) interface{} {
+		if len(args) < 2 {
+			return nil
+		}
+		name := args[0].String()
+		ls := p.listeners[name]
+		for i, l := range ls {
+			if l == args[1] {
+				p.listeners[name] = append(ls[:i:i], ls[i+1:]...)
+				break
+			}
+		}
+		return nil
+	})
+	p.method("getIframe", func([]*js.Object) interface{} {
+		iframe := js.Global.Get("Object").New()
+		iframe.Set("id", p.ElementID)
+		iframe.Set("isConnected", !p.removed)
+		return iframe
+	})
+	p.method("destroy", func([]*js.Object) interface{} {
+		p.destroyed = true
+		// the real player's API methods are gone once destroyed
+		for _, name := range p.methodNames {
+			p.obj.Delete(name)
+		}
+		return nil
+	})
+}
+
+// queuePlaylist handles both the argument and the object syntax of
+// cuePlaylist and loadPlaylist
+func (p *Player) queuePlaylist(args []*js.Object, autoplay bool) {
+	if len(args) == 0 {
+		return
+	}
+	var start float64
+	first := args[0]
+	switch {
+	case js.Global.Get("Array").Call("isArray", first).Bool():
+		p.playlist = stringArray(first)
+		p.index, start = argInt(args, 1), argFloat(args, 2)
+	case first.Get("list") != js.Undefined:
+		p.playlist = []string{first.Get("list").String()}
+		p.index, start = objInt(first, "index"), objFloat(first, "startSeconds")
+	default:
+		p.playlist = []string{first.String()}
+		p.index, start = argInt(args, 1), argFloat(args, 2)
+	}
+	if p.index < 0 || p.index >= len(p.playlist) {
+		p.index = 0
+	}
+	p.load(p.playlist[p.index], start, 0, autoplay)
+}
+
+// videoArgs handles both the argument and the object syntax of the
+// load/cue functions
+func videoArgs(args []*js.Object, idKey string) (id string, start, end float64) {
+	if len(args) == 0 {
+		return "", 0, 0
+	}
+	if obj := args[0]; obj.Get(idKey) != js.Undefined {
+		return obj.Get(idKey).String(), objFloat(obj, "startSeconds"), objFloat(obj, "endSeconds")
+	}
+	return args[0].String(), argFloat(args, 1), 0
+}
+
+func stringArray(arr *js.Object) []string {
+	res := make([]string, arr.Length())
+	for i := range res {
+		res[i] = arr.Index(i).String()
+	}
+	return res
+}
+
+func argFloat(args []*js.Object, i int) float64 {
+	if i >= len(args) || !defined(args[i]) {
+		return 0
+	}
+	return args[i].Float()
+}
+
+func argInt(args []*js.Object, i int) int {
+	if i >= len(args) || !defined(args[i]) {
+		return 0
+	}
+	return args[i].Int()
+}
+
+func objFloat(o *js.Object, key string) float64 {
+	if v := o.Get(key); defined(v) {
+		return v.Float()
+	}
+	return 0
+}
+
+func objInt(o *js.Object, key string) int {
+	if v := o.Get(key); defined(v) {
+		return v.Int()
+	}
+	return 0
+}
+
+func defined(o *js.Object) bool {
+	return o != js.Undefined && o != nil
+}
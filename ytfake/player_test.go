@@ -0,0 +1,382 @@
+//go:build js && !wasm
+
+package ytfake_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/ytfake"
+)
+
+// newPlayer creates a player against fake, ready unless ready is false
+func newPlayer(t *testing.T, fake *ytfake.Fake, ready bool) (*youtube.Player, *ytfake.Player) {
+	t.Helper()
+	p := youtube.NewPlayer("player", youtube.NewProperties())
+	fp := fake.Last()
+	if fp == nil || fp.ElementID != "player" {
+		t.Fatalf("fake player not created for the element: %+v", fp)
+	}
+	if ready {
+		fp.Ready()
+	}
+	return p, fp
+}
+
+func lastCall(t *testing.T, fp *ytfake.Player, method string) ytfake.Call {
+	t.Helper()
+	c, ok := fp.LastCall(method)
+	if !ok {
+		t.Fatalf("%s was not called; calls: %+v", method, fp.Calls())
+	}
+	return c
+}
+
+func TestLoadPlaylistPassesTheIDsAsAnArray(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+	p.LoadPlaylist([]string{"aaaaaaaaaaa", "bbbbbbbbbbb", "ccccccccccc"}, 1, 5, youtube.HD720)
+
+	c := lastCall(t, fp, "loadPlaylist")
+	want := []interface{}{
+		[]interface{}{"aaaaaaaaaaa", "bbbbbbbbbbb", "ccccccccccc"},
+		float64(1), float64(5), "hd720",
+	}
+	if !reflect.DeepEqual(c.Args, want) {
+		t.Errorf("loadPlaylist args = %#v, want %#v", c.Args, want)
+	}
+	if got := p.Playlist(); !reflect.DeepEqual(got, []string{"aaaaaaaaaaa", "bbbbbbbbbbb", "ccccccccccc"}) {
+		t.Errorf("Playlist() = %v", got)
+	}
+	if got := p.PlaylistIndex(); got != 1 {
+		t.Errorf("PlaylistIndex() = %d, want 1", got)
+	}
+	if got := p.CurrentTime(); got != 5 {
+		t.Errorf("CurrentTime() = %v, want 5", got)
+	}
+	if got := p.PlayerState(); got != youtube.Playing {
+		t.Errorf("PlayerState() = %v, want playing", got)
+	}
+}
+
+func TestCuePlaylistPassesTheOptionsObject(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+	opts := youtube.NewCuePlaylistOptions()
+	opts.ListType = youtube.ListTypePlaylist
+	opts.List = "PLabcdefghijklmnop"
+	opts.Index = 2
+	opts.StartSeconds = 30
+	p.CuePlaylist2(opts)
+
+	c := lastCall(t, fp, "cuePlaylist")
+	if len(c.Args) != 1 {
+		t.Fatalf("cuePlaylist args = %#v, want one object", c.Args)
+	}
+	obj, ok := c.Args[0].(map[string]interface{})
+	if !ok {
+		t.Fatalf("cuePlaylist arg = %#v, want an object", c.Args[0])
+	}
+	want := map[string]interface{}{
+		"listType":     "playlist",
+		"list":         "PLabcdefghijklmnop",
+		"index":        float64(2),
+		"startSeconds": float64(30),
+	}
+	for key, v := range want {
+		if obj[key] != v {
+			t.Errorf("cuePlaylist %s = %#v, want %#v", key, obj[key], v)
+		}
+	}
+	if got := p.PlayerState(); got != youtube.VideoCued {
+		t.Errorf("PlayerState() = %v, want video cued", got)
+	}
+}
+
+func TestSetShuffleAndLoopPassBooleans(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+	p.SetShuffle(true)
+	p.SetLoop(true)
+
+	if c := lastCall(t, fp, "setShuffle"); !reflect.DeepEqual(c.Args, []interface{}{true}) {
+		t.Errorf("setShuffle args = %#v, want [true]", c.Args)
+	}
+	if c := lastCall(t, fp, "setLoop"); !reflect.DeepEqual(c.Args, []interface{}{true}) {
+		t.Errorf("setLoop args = %#v, want [true]", c.Args)
+	}
+}
+
+func TestLoadVideoByIDOptions(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+	opts := youtube.NewLoadByIDOptions()
+	opts.VideoID = "dQw4w9WgXcQ"
+	opts.SetRange(10*time.Second, 40*time.Second)
+	p.LoadVideoByID2(opts)
+
+	c := lastCall(t, fp, "loadVideoById")
+	obj, _ := c.Args[0].(map[string]interface{})
+	if obj["videoId"] != "dQw4w9WgXcQ" || obj["startSeconds"] != float64(10) || obj["endSeconds"] != float64(40) {
+		t.Errorf("loadVideoById arg = %#v", c.Args[0])
+	}
+	if got := p.Length(); got != 40*time.Second {
+		t.Errorf("Length() = %v, want 40s", got)
+	}
+	p.SeekToDuration(20*time.Second, true)
+	if got := p.Position(); got != 20*time.Second {
+		t.Errorf("Position() = %v, want 20s", got)
+	}
+}
+
+func TestCommandsAreQueuedUntilReady(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, false)
+	p.CueVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	p.SetVolume(30)
+	p.PlayVideo()
+	if n := len(fp.Calls()); n != 0 {
+		t.Fatalf("%d calls before onReady: %+v", n, fp.Calls())
+	}
+
+	fp.Ready()
+	var methods []string
+	for _, c := range fp.Calls() {
+		methods = append(methods, c.Method)
+	}
+	want := []string{"cueVideoById", "setVolume", "playVideo"}
+	if !reflect.DeepEqual(methods, want) {
+		t.Errorf("calls after onReady = %v, want %v", methods, want)
+	}
+	if got := p.Lifecycle(); got != youtube.LifecycleReady {
+		t.Errorf("Lifecycle() = %v, want ready", got)
+	}
+}
+
+func TestQueueFailRejectsCommands(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, false)
+	p.SetQueuePolicy(youtube.QueueFail)
+
+	p.PlayVideo()
+	if err := p.LastError(); !errors.Is(err, youtube.ErrNotReady) {
+		t.Errorf("LastError() = %v, want ErrNotReady", err)
+	}
+	if err := p.LastError(); err != nil {
+		t.Errorf("LastError() after it was read = %v, want nil", err)
+	}
+	if err := p.Safe().SetVolume(30); !errors.Is(err, youtube.ErrNotReady) {
+		t.Errorf("SafePlayer.SetVolume = %v, want ErrNotReady", err)
+	}
+	// SafePlayer leaves the rejections to LastError as well
+	p.PauseVideo()
+	if err := p.Safe().Mute(); !errors.Is(err, youtube.ErrNotReady) {
+		t.Errorf("SafePlayer.Mute = %v, want ErrNotReady", err)
+	}
+	if err := p.LastError(); !errors.Is(err, youtube.ErrNotReady) {
+		t.Errorf("LastError() after SafePlayer calls = %v, want ErrNotReady", err)
+	}
+
+	fp.Ready()
+	p.PlayVideo()
+	if err := p.LastError(); err != nil {
+		t.Errorf("LastError() once ready = %v, want nil", err)
+	}
+	var methods []string
+	for _, c := range fp.Calls() {
+		methods = append(methods, c.Method)
+	}
+	if !reflect.DeepEqual(methods, []string{"playVideo"}) {
+		t.Errorf("calls = %v, want only the command issued once ready", methods)
+	}
+}
+
+func TestListenersReceiveTypedEvents(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+	var states []youtube.PlayerState
+	l := p.AddEventListener(youtube.OnStateChange, func(e *youtube.Event) {
+		states = append(states, e.AsStateChange().State)
+	})
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	p.PauseVideo()
+	want := []youtube.PlayerState{youtube.Buffering, youtube.Playing, youtube.Paused}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+
+	l.Remove()
+	p.PlayVideo()
+	if len(states) != len(want) {
+		t.Errorf("listener called after Remove: %v", states)
+	}
+	if n := fp.Called("removeEventListener"); n != 1 {
+		t.Errorf("removeEventListener called %d times, want 1", n)
+	}
+}
+
+func TestErrorEventCarriesTheVideo(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+	p.LoadVideoByID("dQw4w9WgXcQ", 12, youtube.Auto)
+
+	var got youtube.ErrorEvent
+	p.AddEventListener(youtube.OnError, func(e *youtube.Event) { got = e.AsError() })
+	fp.InjectError(youtube.ErrVideoNotFound)
+
+	if got.Err != youtube.ErrVideoNotFound || got.VideoID != "dQw4w9WgXcQ" ||
+		got.PlaylistIndex != -1 || got.Time != 12*time.Second {
+		t.Errorf("error event = %+v", got)
+	}
+}
+
+func TestWaitForStateAndEnded(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() { errc <- p.WaitUntilTime(ctx, 200*time.Second) }()
+	time.Sleep(10 * time.Millisecond)
+	fp.Advance(ytfake.DefaultDuration)
+	if err := <-errc; !errors.Is(err, youtube.ErrEnded) {
+		t.Errorf("WaitUntilTime past the end = %v, want ErrEnded", err)
+	}
+	if err := p.WaitForState(ctx, youtube.Ended); err != nil {
+		t.Errorf("WaitForState(ended) = %v", err)
+	}
+}
+
+func TestWaitsOnUntrackedPlayers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	bare := &youtube.Player{Object: js.Global.Get("Object").New()}
+	if err := bare.WaitForState(ctx, youtube.Playing); !errors.Is(err, youtube.ErrNotReady) {
+		t.Errorf("WaitForState on a player without methods = %v, want ErrNotReady", err)
+	}
+
+	obj := js.Global.Get("Object").New()
+	obj.Set("getPlayerState", func() int { return int(youtube.Playing) })
+	obj.Set("addEventListener", func(string, *js.Object) {})
+	obj.Set("removeEventListener", func(string, *js.Object) {})
+	if err := (&youtube.Player{Object: obj}).WaitForState(ctx, youtube.Playing); err != nil {
+		t.Errorf("WaitForState on a player with methods = %v", err)
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	destroyed := false
+	p.OnDestroy(func() { destroyed = true })
+
+	p.Destroy()
+	p.Destroy()
+	if n := fp.Called("destroy"); n != 1 || !fp.Destroyed() || !destroyed {
+		t.Fatalf("destroy called %d times, hook called: %v", n, destroyed)
+	}
+	fp.ResetCalls()
+	p.PlayVideo()
+	p.SeekTo(10, true)
+	if state, t0, vd := p.PlayerState(), p.CurrentTime(), p.VideoData(); state != youtube.Unstarted || t0 != 0 || vd.VideoID != "" {
+		t.Errorf("getters after Destroy: %v, %v, %+v", state, t0, vd)
+	}
+	if n := len(fp.Calls()); n != 0 {
+		t.Errorf("%d calls after Destroy: %+v", n, fp.Calls())
+	}
+}
+
+func TestPlayersRemovedWithoutDestroyAreReleased(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+	events := p.Events(context.Background())
+	released := false
+	p.OnDestroy(func() { released = true })
+
+	fp.RemoveIframe()
+	youtube.SweepDetached()
+	if p.Lifecycle() != youtube.LifecycleReady {
+		t.Fatalf("released by the first sweep: lifecycle %v", p.Lifecycle())
+	}
+	youtube.SweepDetached()
+	for range events {
+	}
+	if !released || p.Lifecycle() != youtube.LifecycleDestroyed {
+		t.Errorf("hook called: %v, lifecycle %v", released, p.Lifecycle())
+	}
+	if fp.Destroyed() {
+		t.Error("destroy called on the removed player")
+	}
+}
+
+func TestPlayersMovedAreKept(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	p, fp := newPlayer(t, fake, true)
+
+	fp.RemoveIframe()
+	youtube.SweepDetached()
+	fp.RestoreIframe()
+	youtube.SweepDetached()
+	fp.RemoveIframe()
+	youtube.SweepDetached()
+	if p.Lifecycle() != youtube.LifecycleReady {
+		t.Errorf("lifecycle = %v, want the moved player kept", p.Lifecycle())
+	}
+	p.Destroy()
+}
+
+func TestOptionsChainEventHandlers(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	var calls []string
+	_, err := youtube.New("player",
+		youtube.WithVideo("dQw4w9WgXcQ"),
+		youtube.WithOnReady(func(*youtube.Player) { calls = append(calls, "first") }),
+		youtube.WithOnReady(func(*youtube.Player) { calls = append(calls, "second") }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.Last().Ready()
+	if want := []string{"first", "second"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("ready handlers called %v, want %v", calls, want)
+	}
+}
+
+func TestNewRejectsInvalidOptions(t *testing.T) {
+	fake := ytfake.Install()
+	defer fake.Uninstall()
+	invalid := map[string]youtube.Option{
+		"a zero height": youtube.WithSize(640, 0),
+		"a nil ref":     youtube.WithRef(nil),
+	}
+	for name, opt := range invalid {
+		if _, err := youtube.New("player", youtube.WithVideo("dQw4w9WgXcQ"), opt); !errors.Is(err, youtube.ErrInvalidOption) {
+			t.Errorf("New with %s = %v, want ErrInvalidOption", name, err)
+		}
+	}
+	if n := len(fake.Players()); n != 0 {
+		t.Errorf("%d players created", n)
+	}
+}
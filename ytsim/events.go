@@ -0,0 +1,99 @@
+package ytsim
+
+import (
+	"context"
+	"time"
+
+	"github.com/iocat/youtube"
+)
+
+// Subscribe calls fn synchronously with every event of the given types, or of
+// every type if none is given. The returned func unsubscribes.
+func (p *Player) Subscribe(fn func(youtube.TypedEvent), types ...youtube.EventType) (cancel func()) {
+	return p.hub.Subscribe(fn, types...)
+}
+
+// Events returns a channel of the events of the given types, or of every type
+// if none is given. Like youtube.Player.Events, it holds
+// youtube.EventStreamBuffer events, drops the oldest one when the receiver
+// falls behind, and is closed once ctx is done or the player is destroyed.
+func (p *Player) Events(ctx context.Context, types ...youtube.EventType) <-chan youtube.TypedEvent {
+	return p.hub.Events(ctx, types...)
+}
+
+// WaitReady blocks until Ready is called
+func (p *Player) WaitReady(ctx context.Context) error {
+	return p.hub.WaitReady(ctx)
+}
+
+// WaitForState blocks until the player reaches the given state
+func (p *Player) WaitForState(ctx context.Context, state youtube.PlayerState) error {
+	return p.wait(ctx, func() bool { return p.state == state })
+}
+
+// WaitUntilTime blocks until the playback time reaches t, or returns
+// youtube.ErrEnded if the video ends before. The virtual clock must be moved
+// by another goroutine, with Advance or Run.
+func (p *Player) WaitUntilTime(ctx context.Context, t time.Duration) error {
+	var ended bool
+	err := p.wait(ctx, func() bool {
+		ended = p.position < t && p.state == youtube.Ended
+		return p.position >= t || ended
+	})
+	if err == nil && ended {
+		return youtube.ErrEnded
+	}
+	return err
+}
+
+// wait re-evaluates done under the lock on every change until it reports
+// true. An onError event aborts the wait with a *youtube.PlayerError.
+func (p *Player) wait(ctx context.Context, done func() bool) error {
+	if err := p.WaitReady(ctx); err != nil {
+		return err
+	}
+	errc := make(chan youtube.ErrorEvent, 1)
+	cancel := p.Subscribe(func(ev youtube.TypedEvent) {
+		select {
+		case errc <- ev.(youtube.ErrorEvent):
+		default:
+		}
+	}, youtube.OnError)
+	defer cancel()
+	for {
+		p.mu.Lock()
+		ok, changed := done(), p.changed
+		p.mu.Unlock()
+		if ok {
+			return nil
+		}
+		select {
+		case ev := <-errc:
+			return ev.PlayerError()
+		case <-changed:
+		case <-p.hub.Done():
+			return youtube.ErrDestroyed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Run advances the virtual clock along with the wall clock every tick until
+// ctx is done or the player is destroyed
+func (p *Player) Run(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			p.Advance(now.Sub(last))
+			last = now
+		case <-p.hub.Done():
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
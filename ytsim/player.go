@@ -0,0 +1,619 @@
+// Package ytsim provides a headless simulation of the Youtube Iframe player
+// written in pure Go. It never calls into JS, so logic written against
+// youtube.Controller can be tested with plain go test on any platform:
+//
+//	p := ytsim.NewPlayer(ytsim.Config{
+//		Durations: map[string]time.Duration{"dQw4w9WgXcQ": 212 * time.Second},
+//	})
+//	p.Ready()
+//	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.HD720)
+//	p.Advance(10 * time.Second)
+//	// p.CurrentTime() == 10
+//
+// The player keeps a virtual clock that only moves with Advance, or with Run
+// to follow the wall clock. It goes through the states of the real player:
+// Unstarted, Buffering, Playing and Ended when loading, VideoCued when cueing,
+// and emits the same typed events.
+package ytsim
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/yttype"
+)
+
+// DefaultDuration is the duration of videos missing from Config.Durations
+const DefaultDuration = 3 * time.Minute
+
+// bufferAhead is how far past the current time the player reports the video
+// as loaded
+const bufferAhead = 30 * time.Second
+
+// Config describes the simulated catalog and player
+type Config struct {
+	// Durations maps a video ID to its duration
+	Durations map[string]time.Duration
+	// DefaultDuration is the duration of the videos missing from Durations.
+	// Zero means the package's DefaultDuration.
+	DefaultDuration time.Duration
+	// Playlists maps a playlist ID to its video IDs, for CuePlaylist2 and
+	// LoadPlaylist2
+	Playlists map[string][]string
+	// BufferDelay is the virtual time spent buffering after every load, play
+	// or seek before the player starts playing
+	BufferDelay time.Duration
+	// Qualities are the quality levels available for every video
+	Qualities []youtube.Quality
+	// Rates are the available playback rates. Defaults to the rates offered
+	// by the real player.
+	Rates []float64
+	// Rand shuffles playlists. Defaults to a deterministic source.
+	Rand *rand.Rand
+}
+
+// Player is a simulated player. It implements youtube.Controller and is safe
+// for concurrent use. Calls made after Destroy are ignored.
+type Player struct {
+	cfg Config
+
+	mu        sync.Mutex
+	state     youtube.PlayerState
+	videoID   string
+	position  time.Duration
+	end       time.Duration
+	buffering time.Duration
+	volume    int
+	muted     bool
+	rate      float64
+	quality   youtube.Quality
+	loop      bool
+	shuffle   bool
+	playlist  []string
+	index     int
+
+	pending []youtube.TypedEvent
+	changed chan struct{} // closed and replaced on every change
+	hub     *youtube.Hub
+}
+
+var _ youtube.Controller = (*Player)(nil)
+
+// NewPlayer creates an unstarted player. It is not ready until Ready is
+// called.
+func NewPlayer(cfg Config) *Player {
+	if cfg.DefaultDuration == 0 {
+		cfg.DefaultDuration = DefaultDuration
+	}
+	if len(cfg.Qualities) == 0 {
+		cfg.Qualities = []youtube.Quality{youtube.HD1080, youtube.HD720, youtube.Large, youtube.Medium, youtube.Small}
+	}
+	if len(cfg.Rates) == 0 {
+		cfg.Rates = []float64{0.25, 0.5, 0.75, 1, 1.25, 1.5, 1.75, 2}
+	}
+	if cfg.Rand == nil {
+		cfg.Rand = rand.New(rand.NewSource(1))
+	}
+	return &Player{
+		cfg:     cfg,
+		state:   youtube.Unstarted,
+		volume:  100,
+		rate:    1,
+		quality: cfg.Qualities[0],
+		changed: make(chan struct{}),
+		hub:     youtube.NewHub(),
+	}
+}
+
+// Ready makes the player ready and emits onReady
+func (p *Player) Ready() {
+	p.hub.MarkReady()
+	p.update(func() {
+		p.emitLocked(youtube.ReadyEvent{})
+	})
+}
+
+// InjectError emits an onError event with the given code and the current
+// video, playlist index and time. Like the real player, the playback stops.
+func (p *Player) InjectError(code youtube.Error) {
+	p.update(func() {
+		if p.state == youtube.Playing || p.state == youtube.Buffering {
+			p.setStateLocked(youtube.Paused)
+		}
+		index := -1
+		if len(p.playlist) > 0 {
+			index = p.index
+		}
+		p.emitLocked(youtube.ErrorEvent{
+			Err:           code,
+			VideoID:       p.videoID,
+			PlaylistIndex: index,
+			Time:          p.position,
+		})
+	})
+}
+
+// Advance moves the virtual clock forward by d. Buffering consumes the clock
+// first, then the playback time moves by d scaled by the playback rate.
+// Reaching the end of a video plays the next playlist entry, wraps around if
+// looping, or ends the playback.
+func (p *Player) Advance(d time.Duration) {
+	p.update(func() {
+		p.advanceLocked(d)
+	})
+}
+
+// update runs fn under the lock, then dispatches the events fn emitted. It
+// does nothing once the player is destroyed.
+func (p *Player) update(fn func()) {
+	if p.hub.Lifecycle() == youtube.LifecycleDestroyed {
+		return
+	}
+	p.mu.Lock()
+	fn()
+	evs := p.pending
+	p.pending = nil
+	close(p.changed)
+	p.changed = make(chan struct{})
+	p.mu.Unlock()
+	for _, ev := range evs {
+		p.hub.Emit(ev)
+	}
+}
+
+// read runs fn under the lock
+func (p *Player) read(fn func()) {
+	p.mu.Lock()
+	fn()
+	p.mu.Unlock()
+}
+
+func (p *Player) emitLocked(ev youtube.TypedEvent) {
+	p.pending = append(p.pending, ev)
+}
+
+func (p *Player) setStateLocked(s youtube.PlayerState) {
+	if p.state == s {
+		return
+	}
+	p.state = s
+	p.emitLocked(youtube.StateChangeEvent{State: s})
+}
+
+func (p *Player) durationLocked() time.Duration {
+	if p.videoID == "" {
+		return 0
+	}
+	if p.end > 0 {
+		return p.end
+	}
+	if d, ok := p.cfg.Durations[p.videoID]; ok {
+		return d
+	}
+	return p.cfg.DefaultDuration
+}
+
+func (p *Player) advanceLocked(d time.Duration) {
+	for d > 0 {
+		switch p.state {
+		case youtube.Buffering:
+			step := d
+			if p.buffering < step {
+				step = p.buffering
+			}
+			p.buffering -= step
+			d -= step
+			if p.buffering == 0 {
+				p.setStateLocked(youtube.Playing)
+			}
+		case youtube.Playing:
+			left := p.durationLocked() - p.position
+			if left <= 0 {
+				p.endLocked()
+				return
+			}
+			toEnd := time.Duration(float64(left) / p.rate)
+			if d < toEnd {
+				p.position += time.Duration(float64(d) * p.rate)
+				return
+			}
+			d -= toEnd
+			p.position = p.durationLocked()
+			p.endLocked()
+		default:
+			return
+		}
+	}
+}
+
+// endLocked handles the end of the current video
+func (p *Player) endLocked() {
+	switch {
+	case p.index+1 < len(p.playlist):
+		p.playAtLocked(p.index + 1)
+	case len(p.playlist) > 0 && p.loop:
+		p.playAtLocked(0)
+	default:
+		p.setStateLocked(youtube.Ended)
+	}
+}
+
+// bufferLocked enters the buffering state, or plays right away without a
+// buffer delay
+func (p *Player) bufferLocked() {
+	p.buffering = p.cfg.BufferDelay
+	if p.buffering == 0 {
+		if p.state != youtube.Playing {
+			p.setStateLocked(youtube.Buffering)
+		}
+		p.setStateLocked(youtube.Playing)
+		return
+	}
+	p.setStateLocked(youtube.Buffering)
+}
+
+func (p *Player) loadLocked(videoID string, start, end float64, autoplay bool) {
+	p.videoID = videoID
+	p.position = yttype.Seconds(start)
+	p.end = yttype.Seconds(end)
+	if !autoplay {
+		p.setStateLocked(youtube.VideoCued)
+		return
+	}
+	p.setStateLocked(youtube.Unstarted)
+	p.bufferLocked()
+}
+
+func (p *Player) playAtLocked(index int) {
+	if index < 0 || index >= len(p.playlist) {
+		return
+	}
+	p.index = index
+	p.loadLocked(p.playlist[index], 0, 0, true)
+}
+
+func (p *Player) playlistLocked(ids []string, index int, start float64, autoplay bool) {
+	p.playlist = append([]string(nil), ids...)
+	if index < 0 || index >= len(p.playlist) {
+		index = 0
+	}
+	p.index = index
+	if len(p.playlist) == 0 {
+		p.videoID = ""
+		p.position = 0
+		p.setStateLocked(youtube.VideoCued)
+		return
+	}
+	p.loadLocked(p.playlist[index], start, 0, autoplay)
+}
+
+// Queueing functions
+
+func (p *Player) LoadVideoByID(vid string, startSec float64, q youtube.Quality) {
+	p.update(func() {
+		p.playlist = nil
+		p.loadLocked(vid, startSec, 0, true)
+	})
+}
+
+func (p *Player) LoadVideoByID2(params *youtube.LoadByIDOptions) {
+	p.update(func() {
+		p.playlist = nil
+		p.loadLocked(params.VideoID, params.StartSeconds, params.EndSeconds, true)
+	})
+}
+
+func (p *Player) CueVideoByID(vid string, startSec float64, q youtube.Quality) {
+	p.update(func() {
+		p.playlist = nil
+		p.loadLocked(vid, startSec, 0, false)
+	})
+}
+
+func (p *Player) CueVideoByID2(params *youtube.LoadByIDOptions) {
+	p.update(func() {
+		p.playlist = nil
+		p.loadLocked(params.VideoID, params.StartSeconds, params.EndSeconds, false)
+	})
+}
+
+func (p *Player) LoadVideoByURL(url string, startSec float64, q youtube.Quality) {
+	p.update(func() {
+		p.playlist = nil
+		p.loadLocked(url, startSec, 0, true)
+	})
+}
+
+func (p *Player) LoadVideoByURL2(params *youtube.LoadByURLOptions) {
+	p.update(func() {
+		p.playlist = nil
+		p.loadLocked(params.MediaContentURL, params.StartSeconds, params.EndSeconds, true)
+	})
+}
+
+func (p *Player) CuePlaylist(ids []string, index int, startSec float64, q youtube.Quality) {
+	p.update(func() {
+		p.playlistLocked(ids, index, startSec, false)
+	})
+}
+
+func (p *Player) CuePlaylist2(params *youtube.CuePlaylistOptions) {
+	p.update(func() {
+		p.playlistLocked(p.cfg.Playlists[params.List], params.Index, params.StartSeconds, false)
+	})
+}
+
+func (p *Player) LoadPlaylist(ids []string, index int, startSec float64, q youtube.Quality) {
+	p.update(func() {
+		p.playlistLocked(ids, index, startSec, true)
+	})
+}
+
+func (p *Player) LoadPlaylist2(params *youtube.CuePlaylistOptions) {
+	p.update(func() {
+		p.playlistLocked(p.cfg.Playlists[params.List], params.Index, params.StartSeconds, true)
+	})
+}
+
+// Playback controls and player settings
+
+func (p *Player) PlayVideo() {
+	p.update(func() {
+		switch p.state {
+		case youtube.Playing, youtube.Buffering:
+			return
+		case youtube.Ended:
+			p.position = 0
+		}
+		if p.videoID != "" {
+			p.bufferLocked()
+		}
+	})
+}
+
+func (p *Player) PauseVideo() {
+	p.update(func() {
+		if p.state == youtube.Playing || p.state == youtube.Buffering {
+			p.setStateLocked(youtube.Paused)
+		}
+	})
+}
+
+func (p *Player) StopVideo() {
+	p.update(func() {
+		p.position = 0
+		p.setStateLocked(youtube.Unstarted)
+	})
+}
+
+// SeekTo moves the playback time. A paused player stays paused; from any
+// other state the player buffers and plays, as the real player does.
+func (p *Player) SeekTo(secs float64, allowSeekAhead bool) {
+	p.update(func() {
+		if p.videoID == "" {
+			return
+		}
+		p.position = yttype.Seconds(math.Max(secs, 0))
+		if d := p.durationLocked(); p.position >= d {
+			p.position = d
+			p.endLocked()
+			return
+		}
+		if p.state != youtube.Paused {
+			p.bufferLocked()
+		}
+	})
+}
+
+func (p *Player) NextVideo() {
+	p.update(func() {
+		next := p.index + 1
+		if next >= len(p.playlist) && p.loop {
+			next = 0
+		}
+		p.playAtLocked(next)
+	})
+}
+
+func (p *Player) PreviousVideo() {
+	p.update(func() {
+		prev := p.index - 1
+		if prev < 0 && p.loop {
+			prev = len(p.playlist) - 1
+		}
+		p.playAtLocked(prev)
+	})
+}
+
+func (p *Player) PlayVideoAt(index int) {
+	p.update(func() {
+		p.playAtLocked(index)
+	})
+}
+
+func (p *Player) Mute() {
+	p.update(func() { p.muted = true })
+}
+
+func (p *Player) UnMute() {
+	p.update(func() { p.muted = false })
+}
+
+func (p *Player) IsMuted() (muted bool) {
+	p.read(func() { muted = p.muted })
+	return
+}
+
+func (p *Player) SetVolume(vol int) {
+	if vol < 0 {
+		vol = 0
+	}
+	if vol > 100 {
+		vol = 100
+	}
+	p.update(func() { p.volume = vol })
+}
+
+func (p *Player) Volume() (vol int) {
+	p.read(func() { vol = p.volume })
+	return
+}
+
+func (p *Player) PlaybackRate() (rate float64) {
+	p.read(func() { rate = p.rate })
+	return
+}
+
+// SetPlaybackRate sets the available rate closest to suggestedRate
+func (p *Player) SetPlaybackRate(suggestedRate float64) {
+	p.update(func() {
+		rate := p.cfg.Rates[0]
+		for _, r := range p.cfg.Rates {
+			if math.Abs(r-suggestedRate) < math.Abs(rate-suggestedRate) {
+				rate = r
+			}
+		}
+		if rate != p.rate {
+			p.rate = rate
+			p.emitLocked(youtube.RateChangeEvent{Rate: rate})
+		}
+	})
+}
+
+func (p *Player) AvailablePlaybackRates() []float64 {
+	return append([]float64(nil), p.cfg.Rates...)
+}
+
+func (p *Player) SetLoop(val bool) {
+	p.update(func() { p.loop = val })
+}
+
+// SetShuffle shuffles the playlist with Config.Rand, keeping the current
+// video playing
+func (p *Player) SetShuffle(val bool) {
+	p.update(func() {
+		p.shuffle = val
+		if !val || len(p.playlist) < 2 {
+			return
+		}
+		current := p.playlist[p.index]
+		p.cfg.Rand.Shuffle(len(p.playlist), func(i, j int) {
+			p.playlist[i], p.playlist[j] = p.playlist[j], p.playlist[i]
+		})
+		for i, id := range p.playlist {
+			if id == current {
+				p.index = i
+				break
+			}
+		}
+	})
+}
+
+// Playback status
+
+func (p *Player) VideoLoadedFraction() (f float64) {
+	p.read(func() {
+		d := p.durationLocked()
+		if d == 0 {
+			return
+		}
+		f = math.Min(1, float64(p.position+bufferAhead)/float64(d))
+	})
+	return
+}
+
+func (p *Player) PlayerState() (s youtube.PlayerState) {
+	p.read(func() { s = p.state })
+	return
+}
+
+func (p *Player) CurrentTime() (t float64) {
+	p.read(func() { t = p.position.Seconds() })
+	return
+}
+
+func (p *Player) PlaybackQuality() (q youtube.Quality) {
+	p.read(func() { q = p.quality })
+	return
+}
+
+// SetPlaybackQuality sets the quality if it is one of the available levels
+func (p *Player) SetPlaybackQuality(suggested youtube.Quality) {
+	p.update(func() {
+		if suggested == p.quality {
+			return
+		}
+		for _, q := range p.cfg.Qualities {
+			if q == suggested {
+				p.quality = q
+				p.emitLocked(youtube.QualityChangeEvent{Quality: q})
+				return
+			}
+		}
+	})
+}
+
+func (p *Player) AvailableQualityLevels() []youtube.Quality {
+	return append([]youtube.Quality(nil), p.cfg.Qualities...)
+}
+
+// Video information
+
+func (p *Player) Duration() (d float64) {
+	p.read(func() { d = p.durationLocked().Seconds() })
+	return
+}
+
+func (p *Player) VideoURL() (url string) {
+	p.read(func() {
+		if p.videoID != "" {
+			url = "https://www.youtube.com/watch?v=" + p.videoID
+		}
+	})
+	return
+}
+
+func (p *Player) VideoEmbedCode() (code string) {
+	p.read(func() {
+		if p.videoID != "" {
+			code = `<iframe width="640" height="360" src="https://www.youtube.com/embed/` +
+				p.videoID + `" frameborder="0" allowfullscreen></iframe>`
+		}
+	})
+	return
+}
+
+// Playlist information
+
+func (p *Player) Playlist() (ids []string) {
+	p.read(func() { ids = append([]string(nil), p.playlist...) })
+	if len(ids) == 0 {
+		return nil
+	}
+	return
+}
+
+func (p *Player) PlaylistIndex() (i int) {
+	p.read(func() { i = p.index })
+	return
+}
+
+// Destroy stops the player. Pending waits return youtube.ErrDestroyed, event
+// streams are closed, subscribers are dropped, the OnDestroy hooks are called
+// and every later call is ignored.
+func (p *Player) Destroy() {
+	p.hub.Destroy(nil)
+}
+
+func (p *Player) Lifecycle() youtube.Lifecycle {
+	return p.hub.Lifecycle()
+}
+
+// OnDestroy registers fn to be called by Destroy, or calls it right away if
+// the player is already destroyed
+func (p *Player) OnDestroy(fn func()) (remove func()) {
+	return p.hub.OnDestroy(fn)
+}
@@ -0,0 +1,211 @@
+package ytsim_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube"
+	"github.com/iocat/youtube/ytsim"
+)
+
+// record collects the events of p until the returned func is called
+func record(p *ytsim.Player, types ...youtube.EventType) (stop func() []youtube.TypedEvent) {
+	var evs []youtube.TypedEvent
+	cancel := p.Subscribe(func(ev youtube.TypedEvent) { evs = append(evs, ev) }, types...)
+	return func() []youtube.TypedEvent {
+		cancel()
+		return evs
+	}
+}
+
+func states(ss ...youtube.PlayerState) []youtube.TypedEvent {
+	evs := make([]youtube.TypedEvent, len(ss))
+	for i, s := range ss {
+		evs[i] = youtube.StateChangeEvent{State: s}
+	}
+	return evs
+}
+
+func TestCuePlayPauseEnded(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{
+		Durations:   map[string]time.Duration{"dQw4w9WgXcQ": time.Minute},
+		BufferDelay: time.Second,
+	})
+	p.Ready()
+	stop := record(p, youtube.OnStateChange)
+
+	p.CueVideoByID("dQw4w9WgXcQ", 10, youtube.Auto)
+	p.PlayVideo()
+	p.Advance(time.Second)
+	p.Advance(20 * time.Second)
+	if got := p.CurrentTime(); got != 30 {
+		t.Errorf("CurrentTime() = %v, want 30", got)
+	}
+	p.PauseVideo()
+	p.Advance(time.Minute)
+	if got := p.CurrentTime(); got != 30 {
+		t.Errorf("CurrentTime() while paused = %v, want 30", got)
+	}
+	p.PlayVideo()
+	p.Advance(time.Hour)
+
+	want := states(youtube.VideoCued, youtube.Buffering, youtube.Playing, youtube.Paused,
+		youtube.Buffering, youtube.Playing, youtube.Ended)
+	if got := stop(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if got := p.CurrentTime(); got != 60 {
+		t.Errorf("CurrentTime() at the end = %v, want 60", got)
+	}
+}
+
+func TestSeekTo(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	p.PauseVideo()
+
+	p.SeekTo(42, true)
+	if got, state := p.CurrentTime(), p.PlayerState(); got != 42 || state != youtube.Paused {
+		t.Errorf("after seeking paused: %v, %v, want 42, paused", got, state)
+	}
+	p.PlayVideo()
+	p.SeekTo(-5, true)
+	if got, state := p.CurrentTime(), p.PlayerState(); got != 0 || state != youtube.Playing {
+		t.Errorf("after seeking before the start: %v, %v, want 0, playing", got, state)
+	}
+	p.SeekTo(ytsim.DefaultDuration.Seconds()+10, true)
+	if got, state := p.CurrentTime(), p.PlayerState(); got != ytsim.DefaultDuration.Seconds() || state != youtube.Ended {
+		t.Errorf("after seeking past the end: %v, %v, want the duration, ended", got, state)
+	}
+}
+
+func TestPlaylistNextAndPrevious(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{
+		Durations: map[string]time.Duration{
+			"aaaaaaaaaaa": 10 * time.Second,
+			"bbbbbbbbbbb": 20 * time.Second,
+			"ccccccccccc": 30 * time.Second,
+		},
+	})
+	p.Ready()
+	p.LoadPlaylist([]string{"aaaaaaaaaaa", "bbbbbbbbbbb", "ccccccccccc"}, 0, 0, youtube.Auto)
+
+	p.Advance(15 * time.Second)
+	if i, got := p.PlaylistIndex(), p.CurrentTime(); i != 1 || got != 5 {
+		t.Errorf("after the first video: index %d at %v, want 1 at 5", i, got)
+	}
+	p.NextVideo()
+	if i, url := p.PlaylistIndex(), p.VideoURL(); i != 2 || url != "https://www.youtube.com/watch?v=ccccccccccc" {
+		t.Errorf("NextVideo: index %d, %s", i, url)
+	}
+	p.NextVideo()
+	if i := p.PlaylistIndex(); i != 2 {
+		t.Errorf("NextVideo past the end without looping: index %d, want 2", i)
+	}
+	p.SetLoop(true)
+	p.NextVideo()
+	if i := p.PlaylistIndex(); i != 0 {
+		t.Errorf("NextVideo past the end while looping: index %d, want 0", i)
+	}
+	p.PreviousVideo()
+	if i := p.PlaylistIndex(); i != 2 {
+		t.Errorf("PreviousVideo from the start while looping: index %d, want 2", i)
+	}
+	p.PreviousVideo()
+	if i, got := p.PlaylistIndex(), p.Duration(); i != 1 || got != 20 {
+		t.Errorf("PreviousVideo: index %d, duration %v, want 1, 20", i, got)
+	}
+
+	p.SetLoop(false)
+	p.PlayVideoAt(2)
+	p.Advance(time.Minute)
+	if i, state := p.PlaylistIndex(), p.PlayerState(); i != 2 || state != youtube.Ended {
+		t.Errorf("after the last video: index %d, %v, want 2, ended", i, state)
+	}
+}
+
+func TestPlaybackRate(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	stop := record(p, youtube.OnPlaybackRateChange)
+
+	p.SetPlaybackRate(2)
+	p.Advance(10 * time.Second)
+	if got := p.CurrentTime(); got != 20 {
+		t.Errorf("CurrentTime() at 2x = %v, want 20", got)
+	}
+	// rounded to the closest available rate
+	p.SetPlaybackRate(0.3)
+	p.SetPlaybackRate(0.25)
+	if got := p.PlaybackRate(); got != 0.25 {
+		t.Errorf("PlaybackRate() = %v, want 0.25", got)
+	}
+
+	want := []youtube.TypedEvent{youtube.RateChangeEvent{Rate: 2}, youtube.RateChangeEvent{Rate: 0.25}}
+	if got := stop(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestEventsStream(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := p.Events(ctx)
+
+	p.Ready()
+	p.CueVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	p.SetPlaybackQuality(youtube.Small)
+	p.InjectError(youtube.ErrVideoNotFound)
+
+	want := []youtube.TypedEvent{
+		youtube.ReadyEvent{},
+		youtube.StateChangeEvent{State: youtube.VideoCued},
+		youtube.QualityChangeEvent{Quality: youtube.Small},
+		youtube.ErrorEvent{Err: youtube.ErrVideoNotFound, VideoID: "dQw4w9WgXcQ", PlaylistIndex: -1},
+	}
+	for i, w := range want {
+		if ev := <-events; ev != w {
+			t.Errorf("event %d = %#v, want %#v", i, ev, w)
+		}
+	}
+
+	p.Destroy()
+	for range events {
+	}
+	p.PlayVideo()
+	if got := p.PlayerState(); got != youtube.VideoCued {
+		t.Errorf("PlayerState() after a call on the destroyed player = %v", got)
+	}
+}
+
+func TestWaits(t *testing.T) {
+	p := ytsim.NewPlayer(ytsim.Config{Durations: map[string]time.Duration{"dQw4w9WgXcQ": time.Minute}})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() { errc <- p.WaitForState(ctx, youtube.Playing) }()
+	p.Ready()
+	p.LoadVideoByID("dQw4w9WgXcQ", 0, youtube.Auto)
+	if err := <-errc; err != nil {
+		t.Errorf("WaitForState(playing) = %v", err)
+	}
+
+	go func() { errc <- p.WaitUntilTime(ctx, 2*time.Minute) }()
+	p.Advance(2 * time.Minute)
+	if err := <-errc; !errors.Is(err, youtube.ErrEnded) {
+		t.Errorf("WaitUntilTime past the end = %v, want ErrEnded", err)
+	}
+
+	go func() { errc <- p.WaitForState(ctx, youtube.Paused) }()
+	p.Destroy()
+	if err := <-errc; !errors.Is(err, youtube.ErrDestroyed) {
+		t.Errorf("pending wait = %v, want ErrDestroyed", err)
+	}
+}
@@ -0,0 +1,109 @@
+package yttype
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error represents the errors returned OnError event. It is a Go error.
+type Error int
+
+const (
+	ErrInvalidParameters Error = 2
+	ErrNonHTML5          Error = 5
+	ErrVideoNotFound     Error = 100
+	ErrNotForEmbedded    Error = 101
+	// ErrNotForEmbeddedInDisguise is the same error as ErrNotForEmbedded:
+	// errors.Is reports either one for both codes.
+	ErrNotForEmbeddedInDisguise Error = 150
+)
+
+func (err Error) String() string {
+	switch err {
+	case ErrInvalidParameters:
+		return `The request contains an invalid parameter value.`
+	case ErrNonHTML5:
+		return `The requested content cannot be played in an HTML5 player or another error related to the HTML5 player has occurred.`
+	case ErrVideoNotFound:
+		return "The video requested was not found."
+	case ErrNotForEmbedded, ErrNotForEmbeddedInDisguise:
+		return "The owner of the requested video does not allow it to be played in embedded players."
+	default:
+		return "unknown"
+	}
+}
+
+func (err Error) Error() string {
+	return fmt.Sprintf("youtube: player error %d: %s", int(err), err.String())
+}
+
+// Is reports whether target is the same error, treating 150 as 101
+func (err Error) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && err.canonical() == t.canonical()
+}
+
+func (err Error) canonical() Error {
+	if err == ErrNotForEmbeddedInDisguise {
+		return ErrNotForEmbedded
+	}
+	return err
+}
+
+// MarshalText encodes the error by its code. Unknown codes are kept, as the
+// player may report codes added after this package.
+func (err Error) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(int(err))), nil
+}
+
+// UnmarshalText decodes an error code
+func (err *Error) UnmarshalText(text []byte) error {
+	code, e := strconv.Atoi(strings.TrimSpace(string(text)))
+	if e != nil || code <= 0 {
+		return fmt.Errorf("yttype: invalid error code %q", text)
+	}
+	*err = Error(code)
+	return nil
+}
+
+// MarshalJSON encodes the error as a JSON number of its code
+func (err Error) MarshalJSON() ([]byte, error) { return err.MarshalText() }
+
+// UnmarshalJSON decodes an error from a JSON number or string of its code
+func (err *Error) UnmarshalJSON(data []byte) error { return unmarshalJSONText(data, err) }
+
+// PlayerError is an Error along with what the player was playing when it
+// occurred
+type PlayerError struct {
+	Err Error
+	// VideoID is the video that failed, if known
+	VideoID string
+	// PlaylistIndex is the position of the video in the playlist, or -1
+	// when no playlist is playing
+	PlaylistIndex int
+	// Time is the playback time at the failure
+	Time time.Duration
+}
+
+func (e *PlayerError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "youtube: player error %d", int(e.Err))
+	if e.VideoID != "" {
+		fmt.Fprintf(&b, " on video %s", e.VideoID)
+	}
+	if e.PlaylistIndex >= 0 {
+		fmt.Fprintf(&b, " at playlist index %d", e.PlaylistIndex)
+	}
+	if e.Time > 0 {
+		fmt.Fprintf(&b, " at %v", e.Time)
+	}
+	return b.String() + ": " + e.Err.String()
+}
+
+// Unwrap returns the Error, so errors.Is(err, ErrVideoNotFound) and
+// errors.As(err, &code) work on a PlayerError
+func (e *PlayerError) Unwrap() error {
+	return e.Err
+}
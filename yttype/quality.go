@@ -0,0 +1,58 @@
+package yttype
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Quality represents the player video's quality
+type Quality string
+
+const (
+	Tiny    Quality = "tiny"
+	Small   Quality = "small"
+	Medium  Quality = "medium"
+	Large   Quality = "large"
+	HD720   Quality = "hd720"
+	HD1080  Quality = "hd1080"
+	HD1440  Quality = "hd1440"
+	HD2160  Quality = "hd2160"
+	HighRes Quality = "highres"
+	// Auto is reported while the player picks the quality itself
+	Auto Quality = "auto"
+)
+
+var qualities = []Quality{Tiny, Small, Medium, Large, HD720, HD1080, HD1440, HD2160, HighRes, Auto}
+
+// ParseQuality parses a quality name, ignoring case
+func ParseQuality(s string) (Quality, error) {
+	q := Quality(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range qualities {
+		if q == known {
+			return q, nil
+		}
+	}
+	return "", fmt.Errorf("yttype: unknown quality %q", s)
+}
+
+// MarshalText encodes the quality as is
+func (q Quality) MarshalText() ([]byte, error) { return []byte(q), nil }
+
+// UnmarshalText decodes a quality with ParseQuality. Empty text decodes to
+// the empty quality, which MarshalText encodes as empty text.
+func (q *Quality) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*q = ""
+		return nil
+	}
+	parsed, err := ParseQuality(string(text))
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// MarshalJSON encodes the quality as a JSON string
+func (q Quality) MarshalJSON() ([]byte, error) { return json.Marshal(string(q)) }
@@ -0,0 +1,77 @@
+package yttype
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PlayerState represents the youtube player's state
+type PlayerState int
+
+const (
+	Unstarted PlayerState = iota - 1
+	Ended
+	Playing
+	Paused
+	Buffering
+	_
+	VideoCued
+)
+
+var stateNames = map[PlayerState]string{
+	Unstarted: "unstarted",
+	Ended:     "ended",
+	Playing:   "playing",
+	Paused:    "paused",
+	Buffering: "buffering",
+	VideoCued: "cued",
+}
+
+func (s PlayerState) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return "PlayerState(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ParsePlayerState parses a state from its name, as returned by String, or
+// from its number, as reported by the player
+func ParsePlayerState(s string) (PlayerState, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for state, name := range stateNames {
+		if s == name {
+			return state, nil
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if _, ok := stateNames[PlayerState(n)]; ok {
+			return PlayerState(n), nil
+		}
+	}
+	return 0, fmt.Errorf("yttype: unknown player state %q", s)
+}
+
+// MarshalText encodes the state by name
+func (s PlayerState) MarshalText() ([]byte, error) {
+	if name, ok := stateNames[s]; ok {
+		return []byte(name), nil
+	}
+	return nil, fmt.Errorf("yttype: unknown player state %d", int(s))
+}
+
+// UnmarshalText decodes a state with ParsePlayerState
+func (s *PlayerState) UnmarshalText(text []byte) error {
+	parsed, err := ParsePlayerState(string(text))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// MarshalJSON encodes the state as a JSON string of its name
+func (s PlayerState) MarshalJSON() ([]byte, error) { return marshalJSONText(s) }
+
+// UnmarshalJSON decodes a state from a JSON string or number
+func (s *PlayerState) UnmarshalJSON(data []byte) error { return unmarshalJSONText(data, s) }
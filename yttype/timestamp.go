@@ -0,0 +1,148 @@
+package yttype
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTimestamp is wrapped by the errors ParseTimestamp returns
+var ErrInvalidTimestamp = errors.New("yttype: invalid timestamp")
+
+// Seconds converts the float seconds the player API uses to a duration,
+// rounded to the nearest microsecond
+func Seconds(s float64) time.Duration {
+	if math.IsNaN(s) || math.IsInf(s, 0) {
+		return 0
+	}
+	return time.Duration(math.Round(s*1e6)) * time.Microsecond
+}
+
+// RangeSeconds converts a playback range to the whole seconds of the start
+// and end player parameters: start is rounded down and end up, so the range
+// is never shortened
+func RangeSeconds(start, end time.Duration) (int, int) {
+	return int(start / time.Second), int((end + time.Second - 1) / time.Second)
+}
+
+// ParseTimestamp parses a non-negative timestamp in any of the forms found in
+// Youtube links and descriptions: seconds, as in 90 or 90.5, a clock, as in
+// 1:02:03 or 2:03, or whole hours, minutes and seconds in that order, as in
+// 1h2m3s, 2m or 90s.
+func ParseTimestamp(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	invalid := fmt.Errorf("%w: %q", ErrInvalidTimestamp, s)
+	if s == "" || s[0] == '-' || s[0] == '+' {
+		return 0, invalid
+	}
+	if strings.Contains(s, ":") {
+		return parseClock(s, invalid)
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if math.IsInf(secs, 0) || math.IsNaN(secs) {
+			return 0, invalid
+		}
+		return Seconds(secs), nil
+	}
+	return parseUnits(strings.ToLower(s), invalid)
+}
+
+// parseUnits parses 1h2m3s timestamps: whole numbers of hours, minutes and
+// seconds, each optional but in that order
+func parseUnits(s string, invalid error) (time.Duration, error) {
+	units := []struct {
+		suffix byte
+		unit   time.Duration
+	}{{'h', time.Hour}, {'m', time.Minute}, {'s', time.Second}}
+	var total time.Duration
+	for _, u := range units {
+		i := strings.IndexByte(s, u.suffix)
+		if i < 0 {
+			continue
+		}
+		n, err := strconv.ParseInt(s[:i], 10, 64)
+		if err != nil || i == 0 || s[0] < '0' || s[0] > '9' || n > int64(math.MaxInt64-total)/int64(u.unit) {
+			return 0, invalid
+		}
+		total += time.Duration(n) * u.unit
+		s = s[i+1:]
+	}
+	if s != "" {
+		return 0, invalid
+	}
+	return total, nil
+}
+
+// parseClock parses [h:]m:ss timestamps. The fields after the first are
+// below 60, and the seconds may have a fraction.
+func parseClock(s string, invalid error) (time.Duration, error) {
+	fields := strings.Split(s, ":")
+	if len(fields) > 3 {
+		return 0, invalid
+	}
+	var total time.Duration
+	for i, f := range fields {
+		last := i == len(fields)-1
+		if f == "" || (i > 0 && len(strings.SplitN(f, ".", 2)[0]) != 2) {
+			return 0, invalid
+		}
+		var (
+			n   float64
+			err error
+		)
+		if last {
+			n, err = strconv.ParseFloat(f, 64)
+		} else {
+			var whole int
+			whole, err = strconv.Atoi(f)
+			n = float64(whole)
+		}
+		if err != nil || n < 0 || (i > 0 && n >= 60) {
+			return 0, invalid
+		}
+		total = total*60 + Seconds(n)
+	}
+	return total, nil
+}
+
+// FormatTimestamp formats d, truncated to whole seconds, as the player shows
+// it: 2:03 under an hour and 1:02:03 otherwise
+func FormatTimestamp(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	h, m, s := clock(d)
+	if h > 0 {
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, h, m, s)
+	}
+	return fmt.Sprintf("%s%d:%02d", sign, m, s)
+}
+
+// FormatTimeParam formats d, truncated to whole seconds, as the t parameter
+// of Youtube links: 1h2m3s, 2m or 0s. Negative durations are formatted as 0s.
+func FormatTimeParam(d time.Duration) string {
+	if d < time.Second {
+		return "0s"
+	}
+	h, m, s := clock(d)
+	var b strings.Builder
+	if h > 0 {
+		fmt.Fprintf(&b, "%dh", h)
+	}
+	if m > 0 {
+		fmt.Fprintf(&b, "%dm", m)
+	}
+	if s > 0 {
+		fmt.Fprintf(&b, "%ds", s)
+	}
+	return b.String()
+}
+
+func clock(d time.Duration) (h, m, s int64) {
+	secs := int64(d / time.Second)
+	return secs / 3600, secs / 60 % 60, secs % 60
+}
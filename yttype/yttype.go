@@ -0,0 +1,177 @@
+// Package yttype holds the types shared by the Youtube player and the code
+// around it: qualities, player states, errors, list types, controls modes and
+// event types. It is written in pure Go, so servers can decode the values sent
+// by players in the browser with the same types.
+//
+// Every type implements encoding.TextMarshaler, encoding.TextUnmarshaler and
+// json.Marshaler. String types are encoded as is; PlayerState and
+// ControlsMode are encoded by name and decoded from their name or number;
+// Error is encoded by its code.
+//
+// ParseTimestamp, FormatTimestamp and FormatTimeParam convert durations from
+// and to the timestamps of Youtube links and descriptions.
+package yttype
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ControlsMode is the controls player parameter
+type ControlsMode int
+
+const (
+	// ControlsNotDisplay has the player controls not displayed in the player
+	ControlsNotDisplay ControlsMode = iota
+	// ControlsDisplayImmediately has the controls displayed immediately and
+	// the Flash player also loads immediately.
+	ControlsDisplayImmediately
+	// ControlsDisplayAfter has the controls displayed, and the Flash player
+	// loaded after the user initiates the video playback.
+	ControlsDisplayAfter
+)
+
+var controlsModeNames = []string{"none", "immediately", "after"}
+
+func (m ControlsMode) String() string {
+	if m >= 0 && int(m) < len(controlsModeNames) {
+		return controlsModeNames[m]
+	}
+	return "ControlsMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// MarshalText encodes the mode by name
+func (m ControlsMode) MarshalText() ([]byte, error) {
+	if m >= 0 && int(m) < len(controlsModeNames) {
+		return []byte(controlsModeNames[m]), nil
+	}
+	return nil, fmt.Errorf("yttype: unknown controls mode %d", int(m))
+}
+
+// UnmarshalText decodes a mode from its name or number
+func (m *ControlsMode) UnmarshalText(text []byte) error {
+	s := strings.ToLower(string(text))
+	for i, name := range controlsModeNames {
+		if s == name {
+			*m = ControlsMode(i)
+			return nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n >= len(controlsModeNames) {
+		return fmt.Errorf("yttype: unknown controls mode %q", text)
+	}
+	*m = ControlsMode(n)
+	return nil
+}
+
+// MarshalJSON encodes the mode as a JSON string of its name
+func (m ControlsMode) MarshalJSON() ([]byte, error) { return marshalJSONText(m) }
+
+// UnmarshalJSON decodes a mode from a JSON string or number
+func (m *ControlsMode) UnmarshalJSON(data []byte) error { return unmarshalJSONText(data, m) }
+
+// ListType is the listType player parameter
+type ListType string
+
+const (
+	// ListTypePlaylist represents playlist
+	ListTypePlaylist ListType = "playlist"
+	// ListTypeSearch represents search list
+	ListTypeSearch ListType = "search"
+	// ListTypeUserUploads represents user uploads
+	ListTypeUserUploads ListType = "user_uploads"
+)
+
+// Valid reports whether t is one of the ListType* constants
+func (t ListType) Valid() bool {
+	switch t {
+	case ListTypePlaylist, ListTypeSearch, ListTypeUserUploads:
+		return true
+	}
+	return false
+}
+
+// MarshalText encodes the list type as is
+func (t ListType) MarshalText() ([]byte, error) { return []byte(t), nil }
+
+// UnmarshalText decodes one of the ListType* constants, or the empty list
+// type from empty text
+func (t *ListType) UnmarshalText(text []byte) error {
+	if lt := ListType(text); lt == "" || lt.Valid() {
+		*t = lt
+		return nil
+	}
+	return fmt.Errorf("yttype: unknown list type %q", text)
+}
+
+// MarshalJSON encodes the list type as a JSON string
+func (t ListType) MarshalJSON() ([]byte, error) { return json.Marshal(string(t)) }
+
+// EventType is the name of a player event
+type EventType string
+
+const (
+	OnReady                 EventType = "onReady"
+	OnStateChange           EventType = "onStateChange"
+	OnPlaybackQualityChange EventType = "onPlaybackQualityChange"
+	OnPlaybackRateChange    EventType = "onPlaybackRateChange"
+	OnError                 EventType = "onError"
+	OnApiChange             EventType = "onApiChange"
+)
+
+// Valid reports whether t is one of the event types of the player
+func (t EventType) Valid() bool {
+	switch t {
+	case OnReady, OnStateChange, OnPlaybackQualityChange, OnPlaybackRateChange, OnError, OnApiChange:
+		return true
+	}
+	return false
+}
+
+// MarshalText encodes the event type as is
+func (t EventType) MarshalText() ([]byte, error) { return []byte(t), nil }
+
+// UnmarshalText decodes one of the event types of the player, or the empty
+// event type from empty text
+func (t *EventType) UnmarshalText(text []byte) error {
+	if et := EventType(text); et == "" || et.Valid() {
+		*t = et
+		return nil
+	}
+	return fmt.Errorf("yttype: unknown event type %q", text)
+}
+
+// MarshalJSON encodes the event type as a JSON string
+func (t EventType) MarshalJSON() ([]byte, error) { return json.Marshal(string(t)) }
+
+// marshalJSONText encodes v as a JSON string of its text
+func marshalJSONText(v interface{ MarshalText() ([]byte, error) }) ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// unmarshalJSONText decodes a JSON string or number with v's UnmarshalText
+func unmarshalJSONText(data []byte, v interface{ UnmarshalText([]byte) error }) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	} else {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		s = n.String()
+	}
+	return v.UnmarshalText([]byte(s))
+}
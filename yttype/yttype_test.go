@@ -0,0 +1,179 @@
+package yttype_test
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube/yttype"
+)
+
+type textValue interface {
+	encoding.TextMarshaler
+	json.Marshaler
+}
+
+func TestTextAndJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		v    textValue
+		text string
+		json string
+	}{
+		{yttype.HD720, "hd720", `"hd720"`},
+		{yttype.Auto, "auto", `"auto"`},
+		{yttype.Quality(""), "", `""`},
+		{yttype.ListTypeUserUploads, "user_uploads", `"user_uploads"`},
+		{yttype.ListType(""), "", `""`},
+		{yttype.OnStateChange, "onStateChange", `"onStateChange"`},
+		{yttype.EventType(""), "", `""`},
+		{yttype.Unstarted, "unstarted", `"unstarted"`},
+		{yttype.VideoCued, "cued", `"cued"`},
+		{yttype.ControlsNotDisplay, "none", `"none"`},
+		{yttype.ControlsDisplayAfter, "after", `"after"`},
+		{yttype.ErrVideoNotFound, "100", `100`},
+		{yttype.Error(999), "999", `999`},
+	}
+	for _, tt := range tests {
+		typ := reflect.TypeOf(tt.v)
+		text, err := tt.v.MarshalText()
+		if err != nil || string(text) != tt.text {
+			t.Errorf("%T(%v).MarshalText() = %q, %v, want %q", tt.v, tt.v, text, err, tt.text)
+		}
+		data, err := json.Marshal(tt.v)
+		if err != nil || string(data) != tt.json {
+			t.Errorf("json.Marshal(%T(%v)) = %s, %v, want %s", tt.v, tt.v, data, err, tt.json)
+		}
+
+		fromText := reflect.New(typ)
+		if err := fromText.Interface().(encoding.TextUnmarshaler).UnmarshalText(text); err != nil {
+			t.Errorf("%s UnmarshalText(%q): %v", typ, text, err)
+		} else if got := fromText.Elem().Interface(); got != tt.v {
+			t.Errorf("%s UnmarshalText(%q) = %v, want %v", typ, text, got, tt.v)
+		}
+		fromJSON := reflect.New(typ)
+		if err := json.Unmarshal(data, fromJSON.Interface()); err != nil {
+			t.Errorf("%s json.Unmarshal(%s): %v", typ, data, err)
+		} else if got := fromJSON.Elem().Interface(); got != tt.v {
+			t.Errorf("%s json.Unmarshal(%s) = %v, want %v", typ, data, got, tt.v)
+		}
+	}
+}
+
+func TestUnmarshalLenient(t *testing.T) {
+	tests := []struct {
+		json string
+		ptr  interface{}
+		want interface{}
+	}{
+		{`"HD1080"`, new(yttype.Quality), yttype.HD1080},
+		{`1`, new(yttype.PlayerState), yttype.Playing},
+		{`"3"`, new(yttype.PlayerState), yttype.Buffering},
+		{`2`, new(yttype.ControlsMode), yttype.ControlsDisplayAfter},
+		{`"150"`, new(yttype.Error), yttype.ErrNotForEmbeddedInDisguise},
+	}
+	for _, tt := range tests {
+		if err := json.Unmarshal([]byte(tt.json), tt.ptr); err != nil {
+			t.Errorf("json.Unmarshal(%s, %T): %v", tt.json, tt.ptr, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.ptr).Elem().Interface(); got != tt.want {
+			t.Errorf("json.Unmarshal(%s, %T) = %v, want %v", tt.json, tt.ptr, got, tt.want)
+		}
+	}
+
+	invalid := []struct {
+		json string
+		ptr  interface{}
+	}{
+		{`"4k"`, new(yttype.Quality)},
+		{`"channel"`, new(yttype.ListType)},
+		{`"onClick"`, new(yttype.EventType)},
+		{`4`, new(yttype.PlayerState)},
+		{`""`, new(yttype.PlayerState)},
+		{`3`, new(yttype.ControlsMode)},
+		{`0`, new(yttype.Error)},
+	}
+	for _, tt := range invalid {
+		if err := json.Unmarshal([]byte(tt.json), tt.ptr); err == nil {
+			t.Errorf("json.Unmarshal(%s, %T) succeeded", tt.json, tt.ptr)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	valid := []struct {
+		s    string
+		want time.Duration
+	}{
+		{"90", 90 * time.Second},
+		{"90.5", 90500 * time.Millisecond},
+		{"0", 0},
+		{"90s", 90 * time.Second},
+		{"2m", 2 * time.Minute},
+		{"1m30s", 90 * time.Second},
+		{"1h2m3s", time.Hour + 2*time.Minute + 3*time.Second},
+		{"1H2M", time.Hour + 2*time.Minute},
+		{"1h3s", time.Hour + 3*time.Second},
+		{"2:03", 2*time.Minute + 3*time.Second},
+		{"1:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"0:05.5", 5500 * time.Millisecond},
+	}
+	for _, tt := range valid {
+		if got, err := yttype.ParseTimestamp(tt.s); err != nil || got != tt.want {
+			t.Errorf("ParseTimestamp(%q) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+
+	invalid := []string{
+		"", "-5", "+5", "100ms", "1.5h", "1.5m", "2m1h", "1s2m", "1h1h", "h", "1x",
+		"1:60", "1:2:03", "1:02:03:04", "NaN", "Inf", "9999999999999h",
+	}
+	for _, s := range invalid {
+		if got, err := yttype.ParseTimestamp(s); !errors.Is(err, yttype.ErrInvalidTimestamp) {
+			t.Errorf("ParseTimestamp(%q) = %v, %v, want ErrInvalidTimestamp", s, got, err)
+		}
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		d            time.Duration
+		stamp, param string
+	}{
+		{0, "0:00", "0s"},
+		{90 * time.Second, "1:30", "1m30s"},
+		{time.Hour + 2*time.Minute + 3*time.Second + 999*time.Millisecond, "1:02:03", "1h2m3s"},
+		{2 * time.Minute, "2:00", "2m"},
+	}
+	for _, tt := range tests {
+		if got := yttype.FormatTimestamp(tt.d); got != tt.stamp {
+			t.Errorf("FormatTimestamp(%v) = %q, want %q", tt.d, got, tt.stamp)
+		}
+		if got := yttype.FormatTimeParam(tt.d); got != tt.param {
+			t.Errorf("FormatTimeParam(%v) = %q, want %q", tt.d, got, tt.param)
+		}
+		if got, err := yttype.ParseTimestamp(tt.param); err != nil || got != tt.d.Truncate(time.Second) {
+			t.Errorf("ParseTimestamp(FormatTimeParam(%v)) = %v, %v", tt.d, got, err)
+		}
+	}
+}
+
+func TestRangeSeconds(t *testing.T) {
+	tests := []struct {
+		start, end         time.Duration
+		wantStart, wantEnd int
+	}{
+		{0, 0, 0, 0},
+		{90 * time.Second, 120 * time.Second, 90, 120},
+		{1200 * time.Millisecond, 1800 * time.Millisecond, 1, 2},
+		{1999 * time.Millisecond, 90001 * time.Millisecond, 1, 91},
+	}
+	for _, tt := range tests {
+		if start, end := yttype.RangeSeconds(tt.start, tt.end); start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("RangeSeconds(%v, %v) = %d, %d, want %d, %d", tt.start, tt.end, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
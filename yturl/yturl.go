@@ -0,0 +1,236 @@
+// Package yturl parses the links users paste to Youtube videos and playlists.
+// It is written in pure Go and can be used on the server as well as in the
+// browser.
+//
+// Parse understands watch, youtu.be, embed, shorts, live and playlist links on
+// the www, m, music and youtube-nocookie hosts, as well as bare video IDs,
+// along with their t, start, end, list, listType and index parameters:
+//
+//	ref, err := yturl.Parse("https://youtu.be/dQw4w9WgXcQ?t=1m30s")
+//	// ref.VideoID == "dQw4w9WgXcQ", ref.Start == 90*time.Second
+package yturl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iocat/youtube/yttype"
+)
+
+var (
+	// ErrNotYoutube is returned for links to other hosts
+	ErrNotYoutube = errors.New("yturl: not a Youtube link")
+	// ErrNoReference is returned for Youtube links to neither a video nor a
+	// playlist, such as the home page
+	ErrNoReference = errors.New("yturl: link refers to no video or playlist")
+	// ErrInvalidVideoID is returned for malformed video IDs
+	ErrInvalidVideoID = errors.New("yturl: invalid video ID")
+	// ErrInvalidPlaylistID is returned for malformed playlist IDs
+	ErrInvalidPlaylistID = errors.New("yturl: invalid playlist ID")
+	// ErrInvalidTime is returned for malformed t, start or end parameters
+	ErrInvalidTime = errors.New("yturl: invalid time")
+	// ErrInvalidIndex is returned for malformed index parameters
+	ErrInvalidIndex = errors.New("yturl: invalid playlist index")
+)
+
+// Ref is what a link refers to
+type Ref struct {
+	// VideoID is the 11 character ID of the video, if any
+	VideoID string
+	// PlaylistID is the value of the list parameter, if any. For the search
+	// and user_uploads list types, it is the search query or the user name.
+	PlaylistID string
+	// ListType is set along with PlaylistID
+	ListType yttype.ListType
+	// Start and End are the playback range requested by the link
+	Start time.Duration
+	End   time.Duration
+	// Index is the zero-based position of the video in the playlist. Links
+	// carry it one-based in their index parameter.
+	Index int
+}
+
+// IsPlaylist reports whether the reference is to a playlist
+func (r *Ref) IsPlaylist() bool {
+	return r.PlaylistID != ""
+}
+
+var hosts = map[string]bool{
+	"youtube.com":              true,
+	"www.youtube.com":          true,
+	"m.youtube.com":            true,
+	"music.youtube.com":        true,
+	"youtube-nocookie.com":     true,
+	"www.youtube-nocookie.com": true,
+}
+
+// pathPrefixes are the paths followed by the video ID
+var pathPrefixes = []string{"/embed/", "/shorts/", "/live/", "/v/", "/e/"}
+
+// Parse parses a link, or a bare video ID, into a Ref
+func Parse(raw string) (*Ref, error) {
+	raw = strings.TrimSpace(raw)
+	if ValidVideoID(raw) {
+		return &Ref{VideoID: raw}, nil
+	}
+	if !strings.ContainsAny(raw, "./:") {
+		// not a host: a malformed bare ID
+		return nil, invalidVideoID(raw)
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotYoutube, err)
+	}
+	host := strings.ToLower(u.Hostname())
+	ref := &Ref{}
+	switch {
+	case host == "youtu.be":
+		ref.VideoID = firstSegment(strings.TrimPrefix(u.Path, "/"))
+	case hosts[host]:
+		ref.VideoID = videoIDFromPath(u.Path)
+		if ref.VideoID == "" && (u.Path == "/watch" || u.Path == "/watch/") {
+			ref.VideoID = u.Query().Get("v")
+			if ref.VideoID == "" {
+				return nil, fmt.Errorf("%w: missing v parameter in %q", ErrInvalidVideoID, raw)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: host %q", ErrNotYoutube, u.Host)
+	}
+	if ref.VideoID == "videoseries" {
+		// /embed/videoseries?list=... embeds a playlist
+		ref.VideoID = ""
+	}
+	if ref.VideoID != "" && !ValidVideoID(ref.VideoID) {
+		return nil, invalidVideoID(ref.VideoID)
+	}
+	if err := ref.parseQuery(u); err != nil {
+		return nil, err
+	}
+	if ref.VideoID == "" && ref.PlaylistID == "" {
+		return nil, fmt.Errorf("%w: %q", ErrNoReference, raw)
+	}
+	return ref, nil
+}
+
+func invalidVideoID(id string) error {
+	return fmt.Errorf("%w: %q must be 11 characters of A-Z, a-z, 0-9, - and _", ErrInvalidVideoID, id)
+}
+
+func (r *Ref) parseQuery(u *url.URL) error {
+	q := u.Query()
+	if list := q.Get("list"); list != "" {
+		r.PlaylistID = list
+		r.ListType = yttype.ListTypePlaylist
+		if lt := q.Get("listType"); lt != "" {
+			r.ListType = yttype.ListType(lt)
+		}
+		if !r.ListType.Valid() {
+			return fmt.Errorf("%w: unknown list type %q", ErrInvalidPlaylistID, r.ListType)
+		}
+		if r.ListType == yttype.ListTypePlaylist && !ValidPlaylistID(list) {
+			return fmt.Errorf("%w: %q", ErrInvalidPlaylistID, list)
+		}
+	}
+	if index := q.Get("index"); index != "" {
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 1 {
+			return fmt.Errorf("%w: %q must be a positive integer", ErrInvalidIndex, index)
+		}
+		r.Index = i - 1
+	}
+
+	// the t parameter may also be in the fragment, e.g. #t=1m30s
+	t := q.Get("t")
+	if t == "" {
+		if frag, err := url.ParseQuery(u.Fragment); err == nil {
+			t = frag.Get("t")
+		}
+	}
+	if t == "" {
+		t = q.Get("start")
+	}
+	var err error
+	if t != "" {
+		if r.Start, err = parseTime(t); err != nil {
+			return err
+		}
+	}
+	if end := q.Get("end"); end != "" {
+		if r.End, err = parseTime(end); err != nil {
+			return err
+		}
+		if r.End <= r.Start {
+			return fmt.Errorf("%w: end %v is not after start %v", ErrInvalidTime, r.End, r.Start)
+		}
+	}
+	return nil
+}
+
+func videoIDFromPath(path string) string {
+	for _, prefix := range pathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return firstSegment(path[len(prefix):])
+		}
+	}
+	return ""
+}
+
+func firstSegment(path string) string {
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+// parseTime parses the t, start and end parameters: seconds, as in 90, or
+// hours, minutes and seconds, as in 1h2m3s, 2m or 90s
+func parseTime(s string) (time.Duration, error) {
+	d, err := yttype.ParseTimestamp(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q must be seconds or of the form 1h2m3s", ErrInvalidTime, s)
+	}
+	return d, nil
+}
+
+// ValidVideoID reports whether id is a well-formed video ID: 11 characters
+// of A-Z, a-z, 0-9, - and _
+func ValidVideoID(id string) bool {
+	return len(id) == 11 && validChars(id)
+}
+
+// NormalizeOrigin checks that origin is only a scheme and a host, as the
+// origin player parameter requires, and strips a trailing slash
+func NormalizeOrigin(origin string) (string, bool) {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", false
+	}
+	return u.Scheme + "://" + u.Host, true
+}
+
+// ValidPlaylistID reports whether id is a well-formed playlist ID, such as
+// PL... playlists, UU... uploads or RD... mixes
+func ValidPlaylistID(id string) bool {
+	return len(id) >= 2 && len(id) <= 64 && validChars(id)
+}
+
+func validChars(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
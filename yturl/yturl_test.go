@@ -0,0 +1,107 @@
+package yturl_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iocat/youtube/yttype"
+	"github.com/iocat/youtube/yturl"
+)
+
+const (
+	id   = "dQw4w9WgXcQ"
+	list = "PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		link string
+		want yturl.Ref
+	}{
+		{id, yturl.Ref{VideoID: id}},
+		{"  " + id + "\n", yturl.Ref{VideoID: id}},
+		{"https://www.youtube.com/watch?v=" + id, yturl.Ref{VideoID: id}},
+		{"http://youtube.com/watch/?v=" + id, yturl.Ref{VideoID: id}},
+		{"www.youtube.com/watch?v=" + id, yturl.Ref{VideoID: id}},
+		{"https://m.youtube.com/watch?v=" + id, yturl.Ref{VideoID: id}},
+		{"https://music.youtube.com/watch?v=" + id, yturl.Ref{VideoID: id}},
+		{"https://WWW.YouTube.com/watch?v=" + id, yturl.Ref{VideoID: id}},
+		{"https://youtu.be/" + id, yturl.Ref{VideoID: id}},
+		{"https://www.youtube.com/embed/" + id, yturl.Ref{VideoID: id}},
+		{"https://www.youtube-nocookie.com/embed/" + id, yturl.Ref{VideoID: id}},
+		{"https://youtube.com/shorts/" + id, yturl.Ref{VideoID: id}},
+		{"https://www.youtube.com/live/" + id + "?feature=share", yturl.Ref{VideoID: id}},
+		{"https://www.youtube.com/v/" + id, yturl.Ref{VideoID: id}},
+		{"https://youtu.be/" + id + "?t=90", yturl.Ref{VideoID: id, Start: 90 * time.Second}},
+		{"https://youtu.be/" + id + "?t=1h2m3s", yturl.Ref{VideoID: id, Start: time.Hour + 2*time.Minute + 3*time.Second}},
+		{"https://www.youtube.com/watch?v=" + id + "#t=1m30s", yturl.Ref{VideoID: id, Start: 90 * time.Second}},
+		{"https://www.youtube.com/embed/" + id + "?start=10&end=20", yturl.Ref{VideoID: id, Start: 10 * time.Second, End: 20 * time.Second}},
+		{"https://www.youtube.com/playlist?list=" + list, yturl.Ref{PlaylistID: list, ListType: yttype.ListTypePlaylist}},
+		{"https://www.youtube.com/embed/videoseries?list=" + list, yturl.Ref{PlaylistID: list, ListType: yttype.ListTypePlaylist}},
+		{"https://www.youtube.com/watch?v=" + id + "&list=" + list + "&index=3",
+			yturl.Ref{VideoID: id, PlaylistID: list, ListType: yttype.ListTypePlaylist, Index: 2}},
+		{"https://www.youtube.com/embed?listType=search&list=cats",
+			yturl.Ref{PlaylistID: "cats", ListType: yttype.ListTypeSearch}},
+	}
+	for _, tt := range tests {
+		ref, err := yturl.Parse(tt.link)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.link, err)
+			continue
+		}
+		if *ref != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.link, *ref, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		link string
+		err  error
+	}{
+		{"dQw4w9WgXc", yturl.ErrInvalidVideoID},
+		{"dQw4w9WgXcQQ", yturl.ErrInvalidVideoID},
+		{"", yturl.ErrInvalidVideoID},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXc", yturl.ErrInvalidVideoID},
+		{"https://www.youtube.com/watch", yturl.ErrInvalidVideoID},
+		{"https://youtu.be/dQw4w9WgXcQ!", yturl.ErrInvalidVideoID},
+		{"https://vimeo.com/123456", yturl.ErrNotYoutube},
+		{"https://youtube.com.example.com/watch?v=" + id, yturl.ErrNotYoutube},
+		{"https://www.youtube.com/", yturl.ErrNoReference},
+		{"https://www.youtube.com/playlist?list=P!", yturl.ErrInvalidPlaylistID},
+		{"https://www.youtube.com/embed?listType=channel&list=x", yturl.ErrInvalidPlaylistID},
+		{"https://youtu.be/" + id + "?index=0&list=" + list, yturl.ErrInvalidIndex},
+		{"https://youtu.be/" + id + "?index=x&list=" + list, yturl.ErrInvalidIndex},
+		{"https://youtu.be/" + id + "?t=1.5h", yturl.ErrInvalidTime},
+		{"https://youtu.be/" + id + "?t=-5", yturl.ErrInvalidTime},
+		{"https://www.youtube.com/embed/" + id + "?end=x", yturl.ErrInvalidTime},
+		{"https://www.youtube.com/embed/" + id + "?start=20&end=10", yturl.ErrInvalidTime},
+	}
+	for _, tt := range tests {
+		if ref, err := yturl.Parse(tt.link); !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q) = %+v, %v, want %v", tt.link, ref, err, tt.err)
+		}
+	}
+}
+
+func TestNormalizeOrigin(t *testing.T) {
+	tests := []struct {
+		origin, want string
+		ok           bool
+	}{
+		{"https://example.com", "https://example.com", true},
+		{"http://example.com:8080/", "http://example.com:8080", true},
+		{"example.com", "", false},
+		{"ftp://example.com", "", false},
+		{"https://example.com/page", "", false},
+		{"https://example.com?q=1", "", false},
+		{"https://user@example.com", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := yturl.NormalizeOrigin(tt.origin); got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeOrigin(%q) = %q, %v, want %q, %v", tt.origin, got, ok, tt.want, tt.ok)
+		}
+	}
+}
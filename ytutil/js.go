@@ -0,0 +1,44 @@
+//go:build !wasm
+
+package ytutil
+
+import "github.com/gopherjs/gopherjs/js"
+
+// apiPresent reports whether YT.Player is already available
+func apiPresent() bool {
+	yt := js.Global.Get("YT")
+	if yt == js.Undefined || yt == nil {
+		return false
+	}
+	if p := yt.Get("Player"); p == js.Undefined || p == nil {
+		return false
+	}
+	loaded := yt.Get("loaded")
+	return loaded == js.Undefined || loaded.Int() == 1
+}
+
+// installReadyCallback sets the global function name to fn, calling the
+// function previously set under that name first
+func installReadyCallback(name string, fn func()) {
+	prev := js.Global.Get(name)
+	js.Global.Set(name, func() {
+		if prev != js.Undefined && prev != nil {
+			prev.Invoke()
+		}
+		fn()
+	})
+}
+
+// injectScript adds a script loading url to the document, replacing any
+// script already loading it, and calls onError if the script fails to load
+func injectScript(url string, onError func()) {
+	doc := js.Global.Get("document")
+	if old := doc.Call("querySelector", `script[src="`+url+`"]`); old != js.Undefined && old != nil {
+		old.Get("parentNode").Call("removeChild", old)
+	}
+	script := doc.Call("createElement", "script")
+	script.Set("src", url)
+	script.Set("type", "text/javascript")
+	script.Call("addEventListener", "error", onError)
+	doc.Get("head").Call("appendChild", script)
+}
@@ -0,0 +1,54 @@
+//go:build js && wasm
+
+package ytutil
+
+import "syscall/js"
+
+// apiPresent reports whether YT.Player is already available
+func apiPresent() bool {
+	yt := js.Global().Get("YT")
+	if yt.Type() != js.TypeObject {
+		return false
+	}
+	if yt.Get("Player").Type() != js.TypeFunction {
+		return false
+	}
+	loaded := yt.Get("loaded")
+	return loaded.IsUndefined() || loaded.Int() == 1
+}
+
+// installReadyCallback sets the global function name to fn, calling the
+// function previously set under that name first. fn runs on a goroutine, as
+// the OnLoaded callbacks it calls may block, which would deadlock the JS
+// event loop. The callback lives as long as the page, so it is never
+// released.
+func installReadyCallback(name string, fn func()) {
+	prev := js.Global().Get(name)
+	js.Global().Set(name, js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if prev.Type() == js.TypeFunction {
+			prev.Invoke()
+		}
+		go fn()
+		return nil
+	}))
+}
+
+// injectScript adds a script loading url to the document, replacing any
+// script already loading it, and calls onError if the script fails to load
+func injectScript(url string, onError func()) {
+	doc := js.Global().Get("document")
+	if old := doc.Call("querySelector", `script[src="`+url+`"]`); !old.IsNull() && !old.IsUndefined() {
+		old.Get("parentNode").Call("removeChild", old)
+	}
+	script := doc.Call("createElement", "script")
+	script.Set("src", url)
+	script.Set("type", "text/javascript")
+	var cb js.Func
+	cb = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		cb.Release()
+		onError()
+		return nil
+	})
+	script.Call("addEventListener", "error", cb)
+	doc.Get("head").Call("appendChild", script)
+}
@@ -1,24 +1,177 @@
 // Package ytutil contains utility functions for setting up the Youtube Iframe API
 // The application, however, can be set up without using this package.
+//
+// Every function of the package is idempotent and safe to call from several
+// independent widgets on the same page: the API script is injected only once
+// and every callback registered with OnLoaded is called.
 package ytutil
 
-import "github.com/gopherjs/gopherjs/js"
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
 
-const youtubeIframeAPISrc = "https://www.youtube.com/iframe_api"
+const (
+	youtubeIframeAPISrc = "https://www.youtube.com/iframe_api"
+	readyCallback       = "onYouTubeIframeAPIReady"
+)
 
-// Load loads the Youtube Iframe API script into the head of the HTML document
+// ErrScriptLoad is returned by Ready when the API script fails to load,
+// e.g. when it is blocked by the browser or the network
+var ErrScriptLoad = errors.New("ytutil: failed to load the Youtube Iframe API script")
+
+// pollInterval is how often the package checks for YT.Player in case the
+// global ready callback was overwritten by another script
+const pollInterval = 100 * time.Millisecond
+
+var (
+	mu        sync.Mutex
+	hooked    bool
+	loaded    bool
+	polling   bool
+	callbacks []func()
+	done      = make(chan struct{}) // closed once the API is ready
+	// failed is closed when the script being loaded fails, and nil when no
+	// script is being loaded
+	failed chan struct{}
+)
+
+// Load loads the Youtube Iframe API script into the head of the HTML document.
+// It does nothing if the API is already available or the script is being
+// loaded. Once the script failed to load, Load tries again.
 func Load() {
-	addScript(youtubeIframeAPISrc)
+	load()
 }
 
-// OnLoaded registers a callback executed when the Youtube Iframe API is ready
+// load starts loading the script if needed and returns the channel closed if
+// it fails, nil if the API is ready
+func load() <-chan struct{} {
+	hook()
+	if apiPresent() {
+		finish()
+		return nil
+	}
+	mu.Lock()
+	if loaded {
+		mu.Unlock()
+		return nil
+	}
+	if failed != nil {
+		ch := failed
+		mu.Unlock()
+		return ch
+	}
+	ch := make(chan struct{})
+	failed = ch
+	mu.Unlock()
+
+	poll()
+	// a script already in the document may have failed unnoticed: it is
+	// replaced, which the guard of the API script against loading twice makes
+	// harmless
+	injectScript(youtubeIframeAPISrc, func() {
+		mu.Lock()
+		if failed == ch {
+			failed = nil
+			close(ch)
+		}
+		mu.Unlock()
+	})
+	return ch
+}
+
+// OnLoaded registers a callback executed when the Youtube Iframe API is ready.
+// The callback is executed right away if the API is already loaded. If the
+// script fails to load, callbacks wait for a later Load to succeed.
 func OnLoaded(fn func()) {
-	js.Global.Set("onYouTubeIframeAPIReady", fn)
+	hook()
+	if apiPresent() {
+		finish()
+	}
+	mu.Lock()
+	if loaded {
+		mu.Unlock()
+		fn()
+		return
+	}
+	callbacks = append(callbacks, fn)
+	mu.Unlock()
+	poll()
+}
+
+// Ready loads the API if needed and blocks until it is ready. It returns
+// ErrScriptLoad if the script fails to load, or ctx.Err() if ctx is done
+// first. Ready can be called again to retry after a failure.
+func Ready(ctx context.Context) error {
+	fail := load()
+	select {
+	case <-done:
+		return nil
+	case <-fail:
+		return ErrScriptLoad
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// hook installs the global ready callback once, chaining any callback that
+// was already installed by another script
+func hook() {
+	mu.Lock()
+	defer mu.Unlock()
+	if hooked {
+		return
+	}
+	hooked = true
+	installReadyCallback(readyCallback, finish)
 }
 
-func addScript(url string) {
-	script := js.Global.Get("document").Call("createElement", "script")
-	script.Set("src", url)
-	script.Set("type", "text/javascript")
-	js.Global.Get("document").Get("head").Call("appendChild", script)
+// poll checks for YT.Player until the API is ready, as the ready callback may
+// be overwritten by another script. It runs while callbacks are pending or a
+// script is being loaded.
+func poll() {
+	mu.Lock()
+	if polling || loaded {
+		mu.Unlock()
+		return
+	}
+	polling = true
+	mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if apiPresent() {
+				finish()
+			}
+			mu.Lock()
+			if loaded || (len(callbacks) == 0 && failed == nil) {
+				polling = false
+				mu.Unlock()
+				return
+			}
+			mu.Unlock()
+		}
+	}()
+}
+
+// finish records that the API is ready and runs the pending callbacks
+func finish() {
+	mu.Lock()
+	if loaded {
+		mu.Unlock()
+		return
+	}
+	loaded = true
+	fns := callbacks
+	callbacks = nil
+	close(done)
+	mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
 }